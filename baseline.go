@@ -0,0 +1,104 @@
+package todo
+
+import (
+	"cmp"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"path"
+	"path/filepath"
+	"slices"
+)
+
+// Fingerprint returns a stable identifier for the TODO.
+// It's derived from the file and the TODO text, so it doesn't change
+// when the TODO moves to a different line in the same file.
+func (t Todo) Fingerprint() string {
+	file := path.Clean(filepath.ToSlash(t.Location.File))
+	sum := sha256.Sum256([]byte(file + "\x00" + t.String()))
+	return hex.EncodeToString(sum[:8])
+}
+
+// BaselineEntry is a TODO recorded in a Baseline.
+type BaselineEntry struct {
+	Fingerprint string `json:"fingerprint"`
+	File        string `json:"file"`
+	Todo        string `json:"todo"`
+}
+
+// Baseline is a snapshot of existing TODOs. It is used to ignore
+// existing TODOs so that only new ones are reported.
+type Baseline struct {
+	Entries []BaselineEntry `json:"todos"`
+}
+
+// NewBaseline returns a baseline containing the TODOs.
+// The entries are sorted by file so the output is stable.
+func NewBaseline(todos []Todo) *Baseline {
+	var b Baseline
+	for _, t := range todos {
+		b.Entries = append(b.Entries, BaselineEntry{
+			Fingerprint: t.Fingerprint(),
+			File:        path.Clean(filepath.ToSlash(t.Location.File)),
+			Todo:        t.String(),
+		})
+	}
+	slices.SortStableFunc(b.Entries, func(x, y BaselineEntry) int {
+		return cmp.Or(
+			cmp.Compare(x.File, y.File),
+			cmp.Compare(x.Todo, y.Todo),
+		)
+	})
+	return &b
+}
+
+// ReadBaseline reads a baseline written by Baseline.Write.
+func ReadBaseline(r io.Reader) (*Baseline, error) {
+	var b Baseline
+	if err := json.NewDecoder(r).Decode(&b); err != nil {
+		return nil, err
+	}
+	return &b, nil
+}
+
+// Write writes the baseline as JSON.
+func (b *Baseline) Write(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(b)
+}
+
+// Filter returns the TODOs which are not in the baseline, and the baseline
+// entries which no longer match any of the TODOs. Each entry matches at most
+// one TODO, so duplicating an existing TODO is reported as new.
+// If files are provided, only the TODOs in those files were scanned, so
+// entries for other files are never reported as resolved.
+func (b *Baseline) Filter(todos []Todo, files ...string) (added []Todo, resolved []BaselineEntry) {
+	scanned := map[string]bool{}
+	for _, f := range files {
+		scanned[path.Clean(filepath.ToSlash(f))] = true
+	}
+	remaining := map[string]int{}
+	for _, e := range b.Entries {
+		remaining[e.Fingerprint]++
+	}
+	for _, t := range todos {
+		fp := t.Fingerprint()
+		if remaining[fp] > 0 {
+			remaining[fp]--
+			continue
+		}
+		added = append(added, t)
+	}
+	for _, e := range b.Entries {
+		if len(scanned) > 0 && !scanned[e.File] {
+			continue
+		}
+		if remaining[e.Fingerprint] > 0 {
+			remaining[e.Fingerprint]--
+			resolved = append(resolved, e)
+		}
+	}
+	return added, resolved
+}
@@ -0,0 +1,68 @@
+package todo
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+func TestFingerprint(t *testing.T) {
+	a := Todo{Location: Location{File: "./a.go", Line: 1}, Description: "one"}
+	b := Todo{Location: Location{File: "a.go", Line: 10}, Description: "one"}
+	c := Todo{Location: Location{File: "b.go", Line: 1}, Description: "one"}
+	if a.Fingerprint() != b.Fingerprint() {
+		t.Errorf("Fingerprint() changed when the TODO moved")
+	}
+	if a.Fingerprint() == c.Fingerprint() {
+		t.Errorf("Fingerprint() is the same for different files")
+	}
+}
+
+func TestBaseline(t *testing.T) {
+	base := ParseText("a.txt", []byte("TODO: one\nTODO: two\nTODO: three\n"))
+	var buf bytes.Buffer
+	if err := NewBaseline(base).Write(&buf); err != nil {
+		t.Fatal(err)
+	}
+	baseline, err := ReadBaseline(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	todos := ParseText("a.txt", []byte("TODO: three\nTODO: one\nTODO: one\nTODO: four\n"))
+	added, resolved := baseline.Filter(todos)
+	var got []string
+	for _, t := range added {
+		got = append(got, t.Location.String()+" "+t.String())
+	}
+	want := []string{
+		"a.txt:3 TODO: one",
+		"a.txt:4 TODO: four",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Filter() added = %q, want %q", got, want)
+	}
+	if len(resolved) != 1 || resolved[0].Todo != "TODO: two" {
+		t.Errorf("Filter() resolved = %+v, want TODO: two", resolved)
+	}
+}
+
+func TestBaselineFilterFiles(t *testing.T) {
+	base := append(
+		ParseText("a.txt", []byte("TODO: one\nTODO: two\n")),
+		ParseText("b.txt", []byte("TODO: three\n"))...,
+	)
+	baseline := NewBaseline(base)
+	// only a.txt was scanned, so b.txt's entry isn't resolved
+	todos := ParseText("a.txt", []byte("TODO: one\n"))
+	added, resolved := baseline.Filter(todos, "./a.txt")
+	if len(added) != 0 {
+		t.Errorf("Filter() added = %v, want none", added)
+	}
+	if len(resolved) != 1 || resolved[0].Todo != "TODO: two" {
+		t.Errorf("Filter() resolved = %+v, want TODO: two", resolved)
+	}
+	// without files, everything was scanned
+	if _, resolved := baseline.Filter(todos); len(resolved) != 2 {
+		t.Errorf("Filter() resolved = %+v, want 2 entries", resolved)
+	}
+}
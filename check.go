@@ -0,0 +1,50 @@
+package todo
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Rules are requirements which TODOs must satisfy.
+type Rules struct {
+	// Require lists the attribute keys every TODO must have.
+	Require []string
+	// Forbid lists the keywords which are not allowed, ignoring case.
+	// The keywords must also be in the registry's keywords to be found.
+	Forbid []string
+}
+
+// Violation is a TODO which does not satisfy the Rules.
+type Violation struct {
+	Todo    Todo
+	Message string
+}
+
+// String returns a string representation.
+func (v Violation) String() string {
+	return fmt.Sprintf("%s %s: %s", v.Todo.Location, v.Todo, v.Message)
+}
+
+// Check returns the violations for each of the TODOs.
+// A TODO may have multiple violations.
+func (r Rules) Check(todos []Todo) []Violation {
+	var violations []Violation
+	for _, t := range todos {
+		if slices.ContainsFunc(r.Forbid, func(k string) bool { return strings.EqualFold(k, t.Keyword) }) {
+			violations = append(violations, Violation{
+				Todo:    t,
+				Message: fmt.Sprintf("forbidden keyword %s", t.Keyword),
+			})
+		}
+		for _, key := range r.Require {
+			if _, ok := t.Attribute(key); !ok {
+				violations = append(violations, Violation{
+					Todo:    t,
+					Message: fmt.Sprintf("missing %s attribute", key),
+				})
+			}
+		}
+	}
+	return violations
+}
@@ -0,0 +1,69 @@
+package todo
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRulesCheck(t *testing.T) {
+	reg := NewRegistry()
+	if err := reg.SetKeywords("TODO", "XXX"); err != nil {
+		t.Fatal(err)
+	}
+	todos := reg.ParseText("a.txt", []byte(
+		"TODO(owner=bob, issue=12): ok\n"+
+			"TODO(owner=bob): no issue\n"+
+			"XXX(owner=bob, issue=1): forbidden\n"+
+			"TODO: nothing\n"+
+			"xxx(owner=bob, issue=2): lowercase\n",
+	))
+	rules := Rules{
+		Require: []string{"owner", "issue"},
+		Forbid:  []string{"XXX"},
+	}
+	var got []string
+	for _, v := range rules.Check(todos) {
+		got = append(got, v.String())
+	}
+	want := []string{
+		"a.txt:2 TODO(owner=bob): no issue: missing issue attribute",
+		"a.txt:3 XXX(owner=bob, issue=1): forbidden: forbidden keyword XXX",
+		"a.txt:4 TODO: nothing: missing owner attribute",
+		"a.txt:4 TODO: nothing: missing issue attribute",
+		"a.txt:5 xxx(owner=bob, issue=2): lowercase: forbidden keyword xxx",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Check() = %q, want %q", got, want)
+	}
+}
+
+func TestParseStaged(t *testing.T) {
+	repo := newTestRepo(t)
+	repo.write("main.go", "package main\n\n// TODO: existing\n")
+	repo.write("old.go", "package main\n\n// TODO: untouched\n")
+	repo.commit("initial")
+	repo.write("main.go", "package main\n\n// TODO: existing\n\n// TODO: staged\n")
+	repo.write("new.go", "package main\n\n// TODO: new file\n")
+	repo.git("add", "main.go", "new.go")
+	repo.write("main.go", "package main\n\n// TODO: existing\n\n// TODO: staged\n// TODO: unstaged\n")
+	r, err := OpenRepository(repo.dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	todos, err := r.ParseStaged()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, todo := range todos {
+		got = append(got, todo.Location.String()+" "+todo.String())
+	}
+	want := []string{
+		"main.go:5 TODO: staged",
+		"new.go:3 TODO: new file",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseStaged() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/icholy/todo"
+)
+
+// defaultBaseline is the default baseline file name.
+const defaultBaseline = ".todo-baseline.json"
+
+// baselineCommand manages the baseline file used by todo check -baseline.
+func baselineCommand(args []string) error {
+	if len(args) == 0 || args[0] != "write" {
+		return errors.New("usage: todo baseline write [flags] [files...]")
+	}
+	fset := flag.NewFlagSet("baseline write", flag.ExitOnError)
+	fset.Usage = func() {
+		fmt.Fprintln(fset.Output(), "usage: todo baseline write [flags] [files...]")
+		fset.PrintDefaults()
+	}
+	output := fset.String("o", defaultBaseline, "the baseline file to write")
+	keywordsFlag(fset)
+	fset.Parse(args[1:])
+	todos, err := parseFiles(fset.Args())
+	if err != nil {
+		return err
+	}
+	f, err := os.Create(*output)
+	if err != nil {
+		return err
+	}
+	if err := todo.NewBaseline(todos).Write(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// readBaseline reads a baseline file.
+func readBaseline(name string) (*todo.Baseline, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	b, err := todo.ReadBaseline(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	return b, nil
+}
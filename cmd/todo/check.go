@@ -0,0 +1,87 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/icholy/todo"
+)
+
+// checkCommand reports the TODOs which don't satisfy the rules.
+// It's intended to be used as a pre-commit hook with the -staged flag.
+func checkCommand(args []string) error {
+	fset := flag.NewFlagSet("check", flag.ExitOnError)
+	fset.Usage = func() {
+		fmt.Fprintln(fset.Output(), "usage: todo check [flags] [files...]")
+		fset.PrintDefaults()
+	}
+	staged := fset.Bool("staged", false, "only check the TODOs on lines changed in the staged files")
+	require := fset.String("require", "", "comma separated list of required attributes")
+	forbid := fset.String("forbid", "", "comma separated list of forbidden keywords")
+	baseline := fset.String("baseline", "", "ignore the TODOs in the baseline `file`")
+	keywordsFlag(fset)
+	where := whereFlag(fset)
+	fset.Parse(args)
+	var rules todo.Rules
+	if *require != "" {
+		rules.Require = strings.Split(*require, ",")
+	}
+	if *forbid != "" {
+		rules.Forbid = strings.Split(*forbid, ",")
+	}
+	// forbidden keywords must be recognized to be reported
+	keywords := todo.DefaultRegistry.Keywords()
+	for _, keyword := range rules.Forbid {
+		if !slices.Contains(keywords, keyword) {
+			keywords = append(keywords, keyword)
+		}
+	}
+	if err := todo.DefaultRegistry.SetKeywords(keywords...); err != nil {
+		return err
+	}
+	var todos []todo.Todo
+	if *staged {
+		if fset.NArg() > 0 {
+			return errors.New("files cannot be used with -staged")
+		}
+		repo, err := todo.OpenRepository(".")
+		if err != nil {
+			return err
+		}
+		defer repo.Close()
+		if todos, err = repo.ParseStaged(); err != nil {
+			return err
+		}
+	} else {
+		var err error
+		if todos, err = parseFiles(fset.Args()); err != nil {
+			return err
+		}
+	}
+	todos = where.Apply(todos)
+	if *baseline != "" {
+		b, err := readBaseline(*baseline)
+		if err != nil {
+			return err
+		}
+		var resolved []todo.BaselineEntry
+		todos, resolved = b.Filter(todos, fset.Args()...)
+		// only the TODOs on staged lines are parsed, so entries can't be reported as resolved
+		if !*staged && fset.NArg() > 0 {
+			for _, e := range resolved {
+				fmt.Printf("%s %s: resolved, remove it from the baseline\n", e.File, e.Todo)
+			}
+		}
+	}
+	violations := rules.Check(todos)
+	for _, v := range violations {
+		fmt.Println(v)
+	}
+	if len(violations) > 0 {
+		return fmt.Errorf("found %d TODO violations", len(violations))
+	}
+	return nil
+}
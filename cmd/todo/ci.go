@@ -0,0 +1,218 @@
+package main
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/icholy/todo"
+)
+
+// writeGitHub writes GitHub Actions workflow commands, which annotate the TODOs in pull requests.
+// Overdue TODOs are errors, high priority TODOs are warnings, and the rest are notices.
+func writeGitHub(w io.Writer, todos []todo.Todo, now time.Time) error {
+	for _, t := range todos {
+		command := "notice"
+		switch t.Severity(now) {
+		case todo.SeverityError:
+			command = "error"
+		case todo.SeverityWarning:
+			command = "warning"
+		}
+		_, err := fmt.Fprintf(w, "::%s file=%s,line=%d,col=%d,title=%s::%s\n",
+			command,
+			githubProperty(filepath.ToSlash(t.Location.File)),
+			t.Location.Line,
+			column(t),
+			githubProperty(keyword(t)),
+			githubData(t.String()),
+		)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// githubData escapes the message of a workflow command.
+func githubData(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A").Replace(s)
+}
+
+// githubProperty escapes a property value of a workflow command.
+func githubProperty(s string) string {
+	return strings.NewReplacer(":", "%3A", ",", "%2C").Replace(githubData(s))
+}
+
+// keyword returns the keyword of the TODO.
+func keyword(t todo.Todo) string {
+	if t.Keyword == "" {
+		return "TODO"
+	}
+	return t.Keyword
+}
+
+// gitlabIssue is an issue in a GitLab Code Quality report.
+type gitlabIssue struct {
+	Description string         `json:"description"`
+	CheckName   string         `json:"check_name"`
+	Fingerprint string         `json:"fingerprint"`
+	Severity    string         `json:"severity"`
+	Location    gitlabLocation `json:"location"`
+}
+
+type gitlabLocation struct {
+	Path  string      `json:"path"`
+	Lines gitlabLines `json:"lines"`
+}
+
+type gitlabLines struct {
+	Begin int `json:"begin"`
+}
+
+// gitlabSeverities maps the severities to the Code Quality severities.
+var gitlabSeverities = map[todo.Severity]string{
+	todo.SeverityHint:    "info",
+	todo.SeverityInfo:    "minor",
+	todo.SeverityWarning: "major",
+	todo.SeverityError:   "critical",
+}
+
+// writeGitLab writes a GitLab Code Quality report.
+func writeGitLab(w io.Writer, todos []todo.Todo, now time.Time) error {
+	issues := []gitlabIssue{}
+	for _, t := range todos {
+		issues = append(issues, gitlabIssue{
+			Description: t.String(),
+			CheckName:   strings.ToLower(keyword(t)),
+			Fingerprint: t.Fingerprint(),
+			Severity:    gitlabSeverities[t.Severity(now)],
+			Location: gitlabLocation{
+				Path:  filepath.ToSlash(t.Location.File),
+				Lines: gitlabLines{Begin: t.Location.Line},
+			},
+		})
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(issues)
+}
+
+// checkstyleReport is a Checkstyle XML report.
+type checkstyleReport struct {
+	XMLName xml.Name         `xml:"checkstyle"`
+	Version string           `xml:"version,attr"`
+	Files   []checkstyleFile `xml:"file"`
+}
+
+type checkstyleFile struct {
+	Name   string            `xml:"name,attr"`
+	Errors []checkstyleError `xml:"error"`
+}
+
+type checkstyleError struct {
+	Line     int    `xml:"line,attr"`
+	Column   int    `xml:"column,attr"`
+	Severity string `xml:"severity,attr"`
+	Message  string `xml:"message,attr"`
+	Source   string `xml:"source,attr"`
+}
+
+// writeCheckstyle writes a Checkstyle XML report, with the TODOs grouped by file.
+// Hints are reported as info, since Checkstyle doesn't have them.
+func writeCheckstyle(w io.Writer, todos []todo.Todo, now time.Time) error {
+	report := checkstyleReport{Version: "4.3"}
+	for _, g := range todo.GroupBy(todos, "file") {
+		f := checkstyleFile{Name: filepath.ToSlash(g.Name)}
+		for _, t := range g.Todos {
+			severity := t.Severity(now)
+			if severity == todo.SeverityHint {
+				severity = todo.SeverityInfo
+			}
+			f.Errors = append(f.Errors, checkstyleError{
+				Line:     t.Location.Line,
+				Column:   column(t),
+				Severity: severity.String(),
+				Message:  t.String(),
+				Source:   "todo." + strings.ToLower(keyword(t)),
+			})
+		}
+		report.Files = append(report.Files, f)
+	}
+	return writeXML(w, report)
+}
+
+// junitSuites is a JUnit XML report.
+type junitSuites struct {
+	XMLName xml.Name     `xml:"testsuites"`
+	Suites  []junitSuite `xml:"testsuite"`
+}
+
+type junitSuite struct {
+	Name     string      `xml:"name,attr"`
+	Tests    int         `xml:"tests,attr"`
+	Failures int         `xml:"failures,attr"`
+	Skipped  int         `xml:"skipped,attr"`
+	Cases    []junitCase `xml:"testcase"`
+}
+
+type junitCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Failure   *junitMessage `xml:"failure"`
+	Skipped   *junitMessage `xml:"skipped"`
+}
+
+type junitMessage struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr,omitempty"`
+	Text    string `xml:",chardata"`
+}
+
+// writeJUnit writes a JUnit XML report with a test suite for each file and a test case
+// for each TODO. Overdue and high priority TODOs are failures, and the rest are skipped.
+func writeJUnit(w io.Writer, todos []todo.Todo, now time.Time) error {
+	report := junitSuites{Suites: []junitSuite{}}
+	for _, g := range todo.GroupBy(todos, "file") {
+		file := filepath.ToSlash(g.Name)
+		suite := junitSuite{Name: file, Tests: len(g.Todos)}
+		for _, t := range g.Todos {
+			c := junitCase{
+				Name:      fmt.Sprintf("%s:%d", file, t.Location.Line),
+				ClassName: file,
+			}
+			msg := &junitMessage{Message: t.String()}
+			switch severity := t.Severity(now); severity {
+			case todo.SeverityError, todo.SeverityWarning:
+				msg.Type = severity.String()
+				msg.Text = t.Line
+				c.Failure = msg
+				suite.Failures++
+			default:
+				c.Skipped = msg
+				suite.Skipped++
+			}
+			suite.Cases = append(suite.Cases, c)
+		}
+		report.Suites = append(report.Suites, suite)
+	}
+	return writeXML(w, report)
+}
+
+// writeXML writes the value as an indented XML document.
+func writeXML(w io.Writer, v any) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
@@ -0,0 +1,159 @@
+package main
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/icholy/todo"
+)
+
+// ciTodos are the TODOs written by the CI format tests.
+// The first is overdue, the second is high priority and the third is low priority.
+var ciTodos = []todo.Todo{
+	{
+		Line:        "// TODO(deadline=2025-01-01): overdue",
+		Location:    todo.Location{File: "a/main.go", Line: 3, Column: 4},
+		Keyword:     "TODO",
+		Description: "overdue",
+		Attributes:  []todo.Attribute{{Key: "deadline", Value: "2025-01-01"}},
+	},
+	{
+		Line:        `/* FIXME(priority=1): <b> & "c" */`,
+		Location:    todo.Location{File: "a/main.go", Line: 7, Column: 4},
+		Keyword:     "FIXME",
+		Description: `<b> & "c"`,
+		Attributes:  []todo.Attribute{{Key: "priority", Value: "1"}},
+	},
+	{
+		Line:        "TODO(priority=4): 50% done",
+		Location:    todo.Location{File: "b/x:y,z.txt", Line: 1},
+		Description: "50% done\r\nnext",
+		Attributes:  []todo.Attribute{{Key: "priority", Value: "4"}},
+	},
+}
+
+func TestWriteCIFormats(t *testing.T) {
+	now := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		format string
+		want   string
+	}{
+		{
+			format: "github",
+			want: `::error file=a/main.go,line=3,col=4,title=TODO::TODO(deadline=2025-01-01): overdue
+::warning file=a/main.go,line=7,col=4,title=FIXME::FIXME(priority=1): <b> & "c"
+::notice file=b/x%3Ay%2Cz.txt,line=1,col=1,title=TODO::TODO(priority=4): 50%25 done%0D%0Anext
+`,
+		},
+		{
+			format: "gitlab",
+			want: `[
+  {
+    "description": "TODO(deadline=2025-01-01): overdue",
+    "check_name": "todo",
+    "fingerprint": "1fe9d508797888b4",
+    "severity": "critical",
+    "location": {
+      "path": "a/main.go",
+      "lines": {
+        "begin": 3
+      }
+    }
+  },
+  {
+    "description": "FIXME(priority=1): \u003cb\u003e \u0026 \"c\"",
+    "check_name": "fixme",
+    "fingerprint": "171a5e5b29b529bf",
+    "severity": "major",
+    "location": {
+      "path": "a/main.go",
+      "lines": {
+        "begin": 7
+      }
+    }
+  },
+  {
+    "description": "TODO(priority=4): 50% done\r\nnext",
+    "check_name": "todo",
+    "fingerprint": "8c0eda45cb1ab83d",
+    "severity": "info",
+    "location": {
+      "path": "b/x:y,z.txt",
+      "lines": {
+        "begin": 1
+      }
+    }
+  }
+]
+`,
+		},
+		{
+			format: "checkstyle",
+			want: `<?xml version="1.0" encoding="UTF-8"?>
+<checkstyle version="4.3">
+  <file name="a/main.go">
+    <error line="3" column="4" severity="error" message="TODO(deadline=2025-01-01): overdue" source="todo.todo"></error>
+    <error line="7" column="4" severity="warning" message="FIXME(priority=1): &lt;b&gt; &amp; &#34;c&#34;" source="todo.fixme"></error>
+  </file>
+  <file name="b/x:y,z.txt">
+    <error line="1" column="1" severity="info" message="TODO(priority=4): 50% done&#xD;&#xA;next" source="todo.todo"></error>
+  </file>
+</checkstyle>
+`,
+		},
+		{
+			format: "junit",
+			want: `<?xml version="1.0" encoding="UTF-8"?>
+<testsuites>
+  <testsuite name="a/main.go" tests="2" failures="2" skipped="0">
+    <testcase name="a/main.go:3" classname="a/main.go">
+      <failure message="TODO(deadline=2025-01-01): overdue" type="error">// TODO(deadline=2025-01-01): overdue</failure>
+    </testcase>
+    <testcase name="a/main.go:7" classname="a/main.go">
+      <failure message="FIXME(priority=1): &lt;b&gt; &amp; &#34;c&#34;" type="warning">/* FIXME(priority=1): &lt;b&gt; &amp; &#34;c&#34; */</failure>
+    </testcase>
+  </testsuite>
+  <testsuite name="b/x:y,z.txt" tests="1" failures="0" skipped="1">
+    <testcase name="b/x:y,z.txt:1" classname="b/x:y,z.txt">
+      <skipped message="TODO(priority=4): 50% done&#xD;&#xA;next"></skipped>
+    </testcase>
+  </testsuite>
+</testsuites>
+`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			var b strings.Builder
+			if err := writeTodos(&b, tt.format, ciTodos, now); err != nil {
+				t.Fatal(err)
+			}
+			if got := b.String(); got != tt.want {
+				t.Errorf("writeTodos(%q) = %s, want %s", tt.format, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGitHubEscape(t *testing.T) {
+	tests := []struct {
+		in       string
+		data     string
+		property string
+	}{
+		{in: "plain", data: "plain", property: "plain"},
+		{in: "50%", data: "50%25", property: "50%25"},
+		{in: "a\r\nb", data: "a%0D%0Ab", property: "a%0D%0Ab"},
+		{in: "a:b,c", data: "a:b,c", property: "a%3Ab%2Cc"},
+		{in: "%3A", data: "%253A", property: "%253A"},
+	}
+	for _, tt := range tests {
+		if got := githubData(tt.in); got != tt.data {
+			t.Errorf("githubData(%q) = %q, want %q", tt.in, got, tt.data)
+		}
+		if got := githubProperty(tt.in); got != tt.property {
+			t.Errorf("githubProperty(%q) = %q, want %q", tt.in, got, tt.property)
+		}
+	}
+}
@@ -0,0 +1,45 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+
+	"github.com/icholy/todo"
+)
+
+// diffCommand reports the TODOs added, removed and modified between two revisions.
+func diffCommand(args []string) error {
+	fset := flag.NewFlagSet("diff", flag.ExitOnError)
+	fset.Usage = func() {
+		fmt.Fprintln(fset.Output(), "usage: todo diff [flags] <base> <head>")
+		fset.PrintDefaults()
+	}
+	summary := fset.Bool("summary", false, "only print the summary")
+	fset.Parse(args)
+	if fset.NArg() != 2 {
+		fset.Usage()
+		return errors.New("expected base and head revisions")
+	}
+	repo, err := todo.OpenRepository(".")
+	if err != nil {
+		return err
+	}
+	defer repo.Close()
+	base, err := repo.Parse(fset.Arg(0))
+	if err != nil {
+		return err
+	}
+	head, err := repo.Parse(fset.Arg(1))
+	if err != nil {
+		return err
+	}
+	changes := todo.Diff(base, head)
+	if !*summary {
+		for _, c := range changes {
+			fmt.Println(c)
+		}
+	}
+	fmt.Println(todo.Summarize(changes))
+	return nil
+}
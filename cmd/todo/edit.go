@@ -0,0 +1,115 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/icholy/todo"
+)
+
+// editCommand modifies the TODOs in place.
+func editCommand(args []string) error {
+	fset := flag.NewFlagSet("edit", flag.ExitOnError)
+	fset.Usage = func() {
+		fmt.Fprintln(fset.Output(), "usage: todo edit [flags] <file[:line]>...")
+		fset.PrintDefaults()
+	}
+	var set []todo.Attribute
+	fset.Func("set", "set an attribute (`key=value`), may be repeated", func(s string) error {
+		key, value, ok := strings.Cut(s, "=")
+		if !ok || key == "" {
+			return fmt.Errorf("expected key=value: %q", s)
+		}
+		set = append(set, todo.Attribute{Key: key, Value: value})
+		return nil
+	})
+	var unset []string
+	fset.Func("unset", "remove an attribute (`key`), may be repeated", func(s string) error {
+		unset = append(unset, s)
+		return nil
+	})
+	var match []todo.Attribute
+	fset.Func("match", "only edit TODOs with the attribute (`key[=value]`), may be repeated", func(s string) error {
+		key, value, _ := strings.Cut(s, "=")
+		match = append(match, todo.Attribute{Key: key, Value: value})
+		return nil
+	})
+	description := fset.String("description", "", "replace the description")
+	dryRun := fset.Bool("n", false, "print the edited TODOs without writing the files")
+	keywordsFlag(fset)
+	fset.Parse(args)
+	if fset.NArg() == 0 {
+		fset.Usage()
+		return errors.New("expected files")
+	}
+	if len(set) == 0 && len(unset) == 0 && *description == "" {
+		return errors.New("nothing to edit, use -set, -unset or -description")
+	}
+	edit := func(t *todo.Todo) bool {
+		for _, m := range match {
+			value, ok := t.Attribute(m.Key)
+			if !ok || (m.Value != "" && value != m.Value) {
+				return false
+			}
+		}
+		for _, a := range set {
+			t.SetAttribute(a.Key, a.Value)
+		}
+		for _, key := range unset {
+			t.RemoveAttribute(key)
+		}
+		if *description != "" {
+			t.Description = *description
+		}
+		return true
+	}
+	for _, arg := range fset.Args() {
+		filename, line := splitLine(arg)
+		source, err := os.ReadFile(filename)
+		if err != nil {
+			return err
+		}
+		todos, err := todo.Parse(filename, source)
+		if err != nil {
+			return err
+		}
+		var edited []todo.Todo
+		for _, t := range todos {
+			if line > 0 && t.Location.Line != line {
+				continue
+			}
+			if edit(&t) {
+				edited = append(edited, t)
+				fmt.Printf("%s %s\n", t.Location, t)
+			}
+		}
+		if len(edited) == 0 || *dryRun {
+			continue
+		}
+		output, err := todo.Rewrite(source, edited)
+		if err != nil {
+			return err
+		}
+		if err := writeFile(filename, output); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// splitLine splits a file:line argument. The line is 0 if it's not present.
+func splitLine(arg string) (string, int) {
+	i := strings.LastIndexByte(arg, ':')
+	if i < 0 {
+		return arg, 0
+	}
+	line, err := strconv.Atoi(arg[i+1:])
+	if err != nil || line <= 0 {
+		return arg, 0
+	}
+	return arg[:i], line
+}
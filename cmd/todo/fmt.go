@@ -0,0 +1,91 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/icholy/todo"
+)
+
+// fmtCommand formats the TODOs in the files like gofmt.
+func fmtCommand(args []string) error {
+	fset := flag.NewFlagSet("fmt", flag.ExitOnError)
+	fset.Usage = func() {
+		fmt.Fprintln(fset.Output(), "usage: todo fmt [flags] <files>...")
+		fset.PrintDefaults()
+	}
+	list := fset.Bool("l", false, "list files whose formatting differs")
+	write := fset.Bool("w", false, "write the result to the source file instead of stdout")
+	diff := fset.Bool("d", false, "display diffs instead of rewriting files")
+	order := fset.String("order", "", "comma separated list of attribute keys in the order they should appear")
+	keywordCase := fset.String("case", "", "change the case of the keywords (upper or lower)")
+	keywordsFlag(fset)
+	fset.Parse(args)
+	if fset.NArg() == 0 {
+		fset.Usage()
+		return errors.New("expected files")
+	}
+	opt := todo.FormatOptions{KeywordCase: *keywordCase}
+	if *order != "" {
+		opt.KeyOrder = strings.Split(*order, ",")
+	}
+	switch opt.KeywordCase {
+	case "", "upper", "lower":
+	default:
+		return fmt.Errorf("invalid case: %q", opt.KeywordCase)
+	}
+	for _, filename := range fset.Args() {
+		source, err := os.ReadFile(filename)
+		if err != nil {
+			return err
+		}
+		output, err := todo.FormatSource(filename, source, opt)
+		if err != nil {
+			return err
+		}
+		changed := !bytes.Equal(source, output)
+		if *list && changed {
+			fmt.Println(filename)
+		}
+		if *diff && changed {
+			fmt.Print(lineDiff(filename, source, output))
+		}
+		if *write && changed {
+			if err := writeFile(filename, output); err != nil {
+				return err
+			}
+		}
+		if !*list && !*diff && !*write {
+			os.Stdout.Write(output)
+		}
+	}
+	return nil
+}
+
+// lineDiff returns a unified diff without context lines.
+// Formatting never adds or removes lines, so the lines are compared one to one.
+func lineDiff(filename string, old, new []byte) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "--- a/%s\n+++ b/%s\n", filename, filename)
+	oldLines := strings.SplitAfter(string(old), "\n")
+	newLines := strings.SplitAfter(string(new), "\n")
+	for i := range min(len(oldLines), len(newLines)) {
+		if oldLines[i] == newLines[i] {
+			continue
+		}
+		fmt.Fprintf(&b, "@@ -%d +%d @@\n-%s+%s", i+1, i+1, withNewline(oldLines[i]), withNewline(newLines[i]))
+	}
+	return b.String()
+}
+
+// withNewline adds a trailing newline if the line doesn't have one.
+func withNewline(line string) string {
+	if strings.HasSuffix(line, "\n") {
+		return line
+	}
+	return line + "\n"
+}
@@ -0,0 +1,196 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"time"
+
+	"github.com/icholy/todo"
+)
+
+// historyCounts are the TODO counts for a single revision.
+type historyCounts struct {
+	Commit   string         `json:"commit"`
+	Time     time.Time      `json:"time"`
+	Total    int            `json:"total"`
+	Keywords map[string]int `json:"keywords"`
+	Owners   map[string]int `json:"owners"`
+}
+
+// historyLifetime is the JSON representation of a todo.Lifetime.
+type historyLifetime struct {
+	File      string    `json:"file"`
+	Line      int       `json:"line"`
+	Todo      string    `json:"todo"`
+	FirstSeen string    `json:"first_seen"`
+	FirstTime time.Time `json:"first_time"`
+	LastSeen  string    `json:"last_seen"`
+	LastTime  time.Time `json:"last_time"`
+	Resolved  string    `json:"resolved,omitempty"`
+	Days      float64   `json:"days"`
+}
+
+// historyCommand reports the TODO counts and lifetimes across the history of a branch.
+func historyCommand(args []string) error {
+	fset := flag.NewFlagSet("history", flag.ExitOnError)
+	fset.Usage = func() {
+		fmt.Fprintln(fset.Output(), "usage: todo history [flags] [revision] [pathspec...]")
+		fset.PrintDefaults()
+	}
+	format := fset.String("format", "csv", "output format (csv or json)")
+	lifetimes := fset.Bool("lifetimes", false, "output the lifetime of each TODO instead of the counts (csv only)")
+	owner := fset.String("owner", "owner", "the attribute which contains the owner")
+	keywordsFlag(fset)
+	fset.Parse(args)
+	if *lifetimes && *format != "csv" {
+		return errors.New("-lifetimes can only be used with -format csv")
+	}
+	rev := "HEAD"
+	if fset.NArg() > 0 {
+		rev = fset.Arg(0)
+	}
+	var paths []string
+	if fset.NArg() > 1 {
+		paths = fset.Args()[1:]
+	}
+	repo, err := todo.OpenRepository(".")
+	if err != nil {
+		return err
+	}
+	defer repo.Close()
+	revs, err := repo.Log(rev)
+	if err != nil {
+		return err
+	}
+	var counts []historyCounts
+	var tracker todo.Tracker
+	for _, r := range revs {
+		todos, err := repo.Parse(r.Hash, paths...)
+		if err != nil {
+			return err
+		}
+		tracker.Add(r, todos)
+		c := historyCounts{
+			Commit:   r.Hash,
+			Time:     r.Time,
+			Total:    len(todos),
+			Keywords: map[string]int{},
+			Owners:   map[string]int{},
+		}
+		for _, t := range todos {
+			c.Keywords[t.Keyword]++
+			if name, ok := t.Attribute(*owner); ok {
+				c.Owners[name]++
+			}
+		}
+		counts = append(counts, c)
+	}
+	var lives []historyLifetime
+	for _, l := range tracker.Lifetimes() {
+		h := historyLifetime{
+			File:      l.Todo.Location.File,
+			Line:      l.Todo.Location.Line,
+			Todo:      l.Todo.String(),
+			FirstSeen: l.FirstSeen.Hash,
+			FirstTime: l.FirstSeen.Time,
+			LastSeen:  l.LastSeen.Hash,
+			LastTime:  l.LastSeen.Time,
+			Days:      l.Duration().Hours() / 24,
+		}
+		if l.Resolved != nil {
+			h.Resolved = l.Resolved.Hash
+		}
+		lives = append(lives, h)
+	}
+	// the average is only known once a TODO has been resolved
+	var average *float64
+	if d, ok := tracker.AverageLifetime(); ok {
+		days := d.Hours() / 24
+		average = &days
+	}
+	switch *format {
+	case "json":
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(map[string]any{
+			"commits":      counts,
+			"lifetimes":    lives,
+			"average_days": average,
+		})
+	case "csv":
+		if *lifetimes {
+			return writeLifetimesCSV(lives, average)
+		}
+		return writeCountsCSV(counts)
+	default:
+		return fmt.Errorf("invalid format: %q", *format)
+	}
+}
+
+// writeCountsCSV writes one row per revision with a column for each keyword and owner.
+func writeCountsCSV(counts []historyCounts) error {
+	keywords := map[string]bool{}
+	owners := map[string]bool{}
+	for _, c := range counts {
+		for k := range c.Keywords {
+			keywords[k] = true
+		}
+		for o := range c.Owners {
+			owners[o] = true
+		}
+	}
+	keywordNames := slices.Sorted(maps.Keys(keywords))
+	ownerNames := slices.Sorted(maps.Keys(owners))
+	w := csv.NewWriter(os.Stdout)
+	header := []string{"commit", "time", "total"}
+	for _, k := range keywordNames {
+		header = append(header, "keyword:"+k)
+	}
+	for _, o := range ownerNames {
+		header = append(header, "owner:"+o)
+	}
+	w.Write(header)
+	for _, c := range counts {
+		row := []string{c.Commit, c.Time.Format(time.RFC3339), fmt.Sprint(c.Total)}
+		for _, k := range keywordNames {
+			row = append(row, fmt.Sprint(c.Keywords[k]))
+		}
+		for _, o := range ownerNames {
+			row = append(row, fmt.Sprint(c.Owners[o]))
+		}
+		w.Write(row)
+	}
+	w.Flush()
+	return w.Error()
+}
+
+// writeLifetimesCSV writes one row per TODO, followed by a row with
+// the average lifetime of the resolved TODOs if there are any.
+func writeLifetimesCSV(lives []historyLifetime, average *float64) error {
+	w := csv.NewWriter(os.Stdout)
+	w.Write([]string{"file", "line", "todo", "first_seen", "first_time", "last_seen", "last_time", "resolved", "days"})
+	for _, l := range lives {
+		w.Write([]string{
+			l.File,
+			fmt.Sprint(l.Line),
+			l.Todo,
+			l.FirstSeen,
+			l.FirstTime.Format(time.RFC3339),
+			l.LastSeen,
+			l.LastTime.Format(time.RFC3339),
+			l.Resolved,
+			fmt.Sprintf("%.1f", l.Days),
+		})
+	}
+	if average != nil {
+		w.Write([]string{"average", "", "", "", "", "", "", "", fmt.Sprintf("%.1f", *average)})
+	}
+	w.Flush()
+	return w.Error()
+}
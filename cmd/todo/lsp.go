@@ -0,0 +1,471 @@
+package main
+
+import (
+	"cmp"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/icholy/todo"
+)
+
+// defaultKeys are the attribute keys offered by completion.
+var defaultKeys = []string{"owner", "issue", "created", "author", "deadline", "priority"}
+
+// lspCommand runs a language server over stdio which reports TODOs as diagnostics.
+func lspCommand(args []string) error {
+	fset := flag.NewFlagSet("lsp", flag.ExitOnError)
+	fset.Usage = func() {
+		fmt.Fprintln(fset.Output(), "usage: todo lsp [flags]")
+		fset.PrintDefaults()
+	}
+	keys := fset.String("keys", strings.Join(defaultKeys, ","), "comma separated list of attribute keys to complete")
+	keywordsFlag(fset)
+	fset.Parse(args)
+	s := &lspServer{
+		conn: newRPCConn(os.Stdin, os.Stdout),
+		docs: map[string]*lspDocument{},
+		keys: strings.Split(*keys, ","),
+	}
+	return s.run()
+}
+
+// lspDocument is an open text document.
+// The text and todos are updated from the parsed document after each change.
+type lspDocument struct {
+	uri    string
+	parsed *todo.Document
+	text   string
+	todos  []todo.Todo
+}
+
+// lspFile is a workspace file which isn't open.
+// The symbols are reused until the file's modification time or size changes.
+type lspFile struct {
+	modTime time.Time
+	size    int64
+	symbols []lspSymbolInformation
+}
+
+// lspServer is a language server for TODOs.
+type lspServer struct {
+	conn     *rpcConn
+	docs     map[string]*lspDocument
+	files    map[string]lspFile
+	keys     []string
+	root     string
+	user     string
+	shutdown bool
+}
+
+// run handles messages until the client sends exit.
+func (s *lspServer) run() error {
+	for {
+		msg, err := s.conn.read()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return err
+		}
+		if msg.Method == "exit" {
+			if !s.shutdown {
+				return errors.New("exit without shutdown")
+			}
+			return nil
+		}
+		result, rerr := s.handle(msg)
+		// notifications don't have a response
+		if msg.ID == nil {
+			if rerr != nil {
+				fmt.Fprintf(os.Stderr, "%s: %s\n", msg.Method, rerr.Message)
+			}
+			continue
+		}
+		resp := &rpcMessage{ID: msg.ID, Error: rerr}
+		// a successful response must have a result, even if it's null
+		if rerr == nil {
+			if resp.Result, err = json.Marshal(result); err != nil {
+				return err
+			}
+		}
+		if err := s.conn.write(resp); err != nil {
+			return err
+		}
+	}
+}
+
+// handle dispatches a message to its handler.
+func (s *lspServer) handle(msg *rpcMessage) (any, *rpcError) {
+	switch msg.Method {
+	case "initialize":
+		var params lspInitializeParams
+		if err := json.Unmarshal(msg.Params, &params); err != nil {
+			return nil, &rpcError{Code: codeInvalidParams, Message: err.Error()}
+		}
+		return s.initialize(params), nil
+	case "initialized":
+		return nil, nil
+	case "shutdown":
+		s.shutdown = true
+		return nil, nil
+	case "textDocument/didOpen":
+		var params lspDocumentParams
+		if err := json.Unmarshal(msg.Params, &params); err != nil {
+			return nil, &rpcError{Code: codeInvalidParams, Message: err.Error()}
+		}
+		file := uriToPath(params.TextDocument.URI)
+		source := []byte(params.TextDocument.Text)
+		// prefer the language the editor reports, and fall back to detection
+		lang, _ := todo.DefaultRegistry.Lookup(params.TextDocument.LanguageID)
+		parsed, err := todo.NewDocument(file, source, lang)
+		if err != nil {
+			return nil, &rpcError{Code: codeInvalidParams, Message: err.Error()}
+		}
+		if old, ok := s.docs[params.TextDocument.URI]; ok {
+			old.parsed.Close()
+		}
+		doc := &lspDocument{uri: params.TextDocument.URI, parsed: parsed}
+		s.docs[doc.uri] = doc
+		s.publish(doc)
+		return nil, nil
+	case "textDocument/didChange":
+		var params lspDidChangeParams
+		if err := json.Unmarshal(msg.Params, &params); err != nil {
+			return nil, &rpcError{Code: codeInvalidParams, Message: err.Error()}
+		}
+		doc, ok := s.docs[params.TextDocument.URI]
+		if !ok {
+			return nil, &rpcError{Code: codeInvalidParams, Message: "document is not open"}
+		}
+		// the document is reparsed incrementally
+		for _, c := range params.ContentChanges {
+			var err error
+			if c.Range == nil {
+				_, err = doc.parsed.Update([]byte(c.Text))
+			} else {
+				text := string(doc.parsed.Source())
+				_, err = doc.parsed.Edit(todo.TextEdit{
+					Start: offsetAt(text, c.Range.Start),
+					End:   offsetAt(text, c.Range.End),
+					Text:  c.Text,
+				})
+			}
+			if err != nil {
+				return nil, &rpcError{Code: codeInvalidParams, Message: err.Error()}
+			}
+		}
+		s.publish(doc)
+		return nil, nil
+	case "textDocument/didClose":
+		var params lspDocumentParams
+		if err := json.Unmarshal(msg.Params, &params); err != nil {
+			return nil, &rpcError{Code: codeInvalidParams, Message: err.Error()}
+		}
+		if doc, ok := s.docs[params.TextDocument.URI]; ok {
+			doc.parsed.Close()
+			delete(s.docs, params.TextDocument.URI)
+		}
+		s.conn.notify("textDocument/publishDiagnostics", lspPublishDiagnosticsParams{
+			URI:         params.TextDocument.URI,
+			Diagnostics: []lspDiagnostic{},
+		})
+		return nil, nil
+	case "textDocument/hover":
+		return s.withDocument(msg, s.hover)
+	case "textDocument/codeAction":
+		return s.withDocument(msg, s.codeActions)
+	case "textDocument/completion":
+		return s.withDocument(msg, s.completion)
+	case "workspace/symbol":
+		var params lspWorkspaceSymbolParams
+		if err := json.Unmarshal(msg.Params, &params); err != nil {
+			return nil, &rpcError{Code: codeInvalidParams, Message: err.Error()}
+		}
+		return s.symbols(params.Query), nil
+	default:
+		if msg.ID == nil {
+			return nil, nil
+		}
+		return nil, &rpcError{Code: codeMethodNotFound, Message: "method not found: " + msg.Method}
+	}
+}
+
+// withDocument decodes the document params and calls the handler with the open document.
+func (s *lspServer) withDocument(msg *rpcMessage, handler func(*lspDocument, lspDocumentParams) any) (any, *rpcError) {
+	var params lspDocumentParams
+	if err := json.Unmarshal(msg.Params, &params); err != nil {
+		return nil, &rpcError{Code: codeInvalidParams, Message: err.Error()}
+	}
+	doc, ok := s.docs[params.TextDocument.URI]
+	if !ok {
+		return nil, nil
+	}
+	return handler(doc, params), nil
+}
+
+// initialize returns the server capabilities.
+func (s *lspServer) initialize(params lspInitializeParams) any {
+	if params.RootURI != "" {
+		s.root = uriToPath(params.RootURI)
+	}
+	cmd := exec.Command("git", "config", "user.name")
+	cmd.Dir = s.root
+	if out, err := cmd.Output(); err == nil {
+		s.user = strings.TrimSpace(string(out))
+	}
+	return map[string]any{
+		"capabilities": map[string]any{
+			"textDocumentSync":        2, // incremental
+			"hoverProvider":           true,
+			"codeActionProvider":      true,
+			"workspaceSymbolProvider": true,
+			"completionProvider": map[string]any{
+				"triggerCharacters": []string{"(", ","},
+			},
+		},
+		"serverInfo": map[string]any{"name": "todo"},
+	}
+}
+
+// publish publishes the document's TODOs as diagnostics.
+func (s *lspServer) publish(doc *lspDocument) {
+	doc.text = string(doc.parsed.Source())
+	doc.todos = doc.parsed.Todos()
+	now := time.Now()
+	diagnostics := []lspDiagnostic{}
+	for _, t := range doc.todos {
+		diagnostics = append(diagnostics, lspDiagnostic{
+			Range:    doc.spanRange(t.Span),
+			Severity: lspSeverity(t.Severity(now)),
+			Source:   "todo",
+			Message:  t.String(),
+		})
+	}
+	s.conn.notify("textDocument/publishDiagnostics", lspPublishDiagnosticsParams{
+		URI:         doc.uri,
+		Diagnostics: diagnostics,
+	})
+}
+
+// lspSeverity converts a severity to an LSP diagnostic severity.
+func lspSeverity(s todo.Severity) int {
+	switch s {
+	case todo.SeverityError:
+		return 1
+	case todo.SeverityWarning:
+		return 2
+	case todo.SeverityHint:
+		return 4
+	default:
+		return 3
+	}
+}
+
+// spanRange converts a span to an LSP range.
+func (d *lspDocument) spanRange(span todo.Span) lspRange {
+	return lspRange{
+		Start: positionAt(d.text, span.Start),
+		End:   positionAt(d.text, span.End),
+	}
+}
+
+// todoAt returns the TODO on the line of the position.
+func (d *lspDocument) todoAt(pos lspPosition) (todo.Todo, bool) {
+	for _, t := range d.todos {
+		if t.Location.Line == pos.Line+1 {
+			return t, true
+		}
+	}
+	return todo.Todo{}, false
+}
+
+// hover shows all the attributes of the TODO at the position.
+func (s *lspServer) hover(doc *lspDocument, params lspDocumentParams) any {
+	t, ok := doc.todoAt(params.Position)
+	if !ok {
+		return nil
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "**%s**: %s\n", cmp.Or(t.Keyword, "TODO"), t.Description)
+	if len(t.Attributes) > 0 {
+		b.WriteString("\n| Attribute | Value |\n| --- | --- |\n")
+		for _, a := range t.Attributes {
+			fmt.Fprintf(&b, "| %s | %s |\n", tableCell(a.Key), tableCell(a.Value))
+		}
+	}
+	if d, ok := t.Deadline(); ok && t.Overdue(time.Now()) {
+		fmt.Fprintf(&b, "\nOverdue since %s\n", d.Format(time.DateOnly))
+	}
+	return lspHover{
+		Contents: lspMarkupContent{Kind: "markdown", Value: b.String()},
+		Range:    doc.spanRange(t.Span),
+	}
+}
+
+// tableCell escapes the text for a markdown table cell.
+func tableCell(text string) string {
+	text = strings.ReplaceAll(text, "|", `\|`)
+	return strings.ReplaceAll(text, "\n", " ")
+}
+
+// codeActions returns the actions to stamp, assign or convert the TODOs in the range.
+func (s *lspServer) codeActions(doc *lspDocument, params lspDocumentParams) any {
+	actions := []lspCodeAction{}
+	action := func(title string, t todo.Todo) {
+		actions = append(actions, lspCodeAction{
+			Title: title,
+			Kind:  "refactor.rewrite",
+			Edit: lspWorkspaceEdit{
+				Changes: map[string][]lspTextEdit{
+					doc.uri: {{Range: doc.spanRange(t.Span), NewText: t.Text()}},
+				},
+			},
+		})
+	}
+	for _, t := range doc.todos {
+		line := t.Location.Line - 1
+		if line < params.Range.Start.Line || line > params.Range.End.Line {
+			continue
+		}
+		if _, ok := t.Attribute("created"); !ok {
+			stamped := editTodo(t, func(t *todo.Todo) {
+				t.SetAttribute("created", time.Now().Format(time.DateOnly))
+				if _, ok := t.Attribute("author"); !ok && s.user != "" {
+					t.SetAttribute("author", s.user)
+				}
+			})
+			action("Stamp TODO", stamped)
+		}
+		if owner, _ := t.Attribute("owner"); s.user != "" && owner != s.user {
+			assigned := editTodo(t, func(t *todo.Todo) {
+				t.SetAttribute("owner", s.user)
+			})
+			action("Assign TODO to "+s.user, assigned)
+		}
+		for _, keyword := range todo.DefaultRegistry.Keywords() {
+			if keyword == t.Keyword {
+				continue
+			}
+			converted := editTodo(t, func(t *todo.Todo) {
+				t.Keyword = keyword
+			})
+			action("Convert to "+keyword, converted)
+		}
+	}
+	return actions
+}
+
+// editTodo returns a modified copy of the TODO.
+func editTodo(t todo.Todo, edit func(t *todo.Todo)) todo.Todo {
+	t.Attributes = slices.Clone(t.Attributes)
+	edit(&t)
+	return t
+}
+
+// completion completes the attribute keys inside a TODO's parentheses.
+func (s *lspServer) completion(doc *lspDocument, params lspDocumentParams) any {
+	items := []lspCompletionItem{}
+	offset := offsetAt(doc.text, params.Position)
+	line := doc.text[strings.LastIndexByte(doc.text[:offset], '\n')+1 : offset]
+	open := -1
+	for _, keyword := range todo.DefaultRegistry.Keywords() {
+		if i := strings.LastIndex(line, keyword+"("); i >= 0 {
+			open = max(open, i+len(keyword))
+		}
+	}
+	if open < 0 || strings.ContainsRune(line[open:], ')') {
+		return items
+	}
+	// don't complete values
+	current := line[max(open, strings.LastIndexByte(line, ','))+1:]
+	if strings.ContainsRune(current, '=') {
+		return items
+	}
+	keys := slices.Clone(s.keys)
+	for _, d := range s.docs {
+		for _, t := range d.todos {
+			for _, a := range t.Attributes {
+				if !slices.Contains(keys, a.Key) {
+					keys = append(keys, a.Key)
+				}
+			}
+		}
+	}
+	for _, key := range keys {
+		if key == "" || strings.Contains(line[open:], key+"=") {
+			continue
+		}
+		items = append(items, lspCompletionItem{Label: key, Kind: completionKindProperty})
+	}
+	return items
+}
+
+// symbols searches the TODOs in the open documents and the workspace.
+// Workspace files are only parsed when they have changed since the last search.
+func (s *lspServer) symbols(query string) any {
+	symbols := []lspSymbolInformation{}
+	add := func(candidates []lspSymbolInformation) {
+		for _, sym := range candidates {
+			if strings.Contains(strings.ToLower(sym.Name), strings.ToLower(query)) {
+				symbols = append(symbols, sym)
+			}
+		}
+	}
+	for _, doc := range s.docs {
+		add(todoSymbols(doc.uri, doc.text, doc.todos))
+	}
+	if s.root == "" {
+		return symbols
+	}
+	files := map[string]lspFile{}
+	walkFiles(s.root, func(path string) error {
+		uri := pathToURI(path)
+		if _, ok := s.docs[uri]; ok {
+			return nil
+		}
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil
+		}
+		f, ok := s.files[path]
+		if !ok || !f.modTime.Equal(info.ModTime()) || f.size != info.Size() {
+			source, todos, err := parseFile(path)
+			if err != nil {
+				return nil
+			}
+			f = lspFile{
+				modTime: info.ModTime(),
+				size:    info.Size(),
+				symbols: todoSymbols(uri, string(source), todos),
+			}
+		}
+		files[path] = f
+		add(f.symbols)
+		return nil
+	})
+	// the files which no longer exist are dropped
+	s.files = files
+	return symbols
+}
+
+// todoSymbols returns a symbol for each of the TODOs in the text.
+func todoSymbols(uri, text string, todos []todo.Todo) []lspSymbolInformation {
+	doc := &lspDocument{text: text}
+	var symbols []lspSymbolInformation
+	for _, t := range todos {
+		symbols = append(symbols, lspSymbolInformation{
+			Name:     t.String(),
+			Kind:     symbolKindString,
+			Location: lspLocation{URI: uri, Range: doc.spanRange(t.Span)},
+		})
+	}
+	return symbols
+}
@@ -0,0 +1,243 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/textproto"
+	"net/url"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// rpcMessage is a JSON-RPC 2.0 request, notification or response.
+// The Result of a successful response is always set, a null result is the JSON null.
+type rpcMessage struct {
+	JSONRPC string           `json:"jsonrpc"`
+	ID      *json.RawMessage `json:"id,omitempty"`
+	Method  string           `json:"method,omitempty"`
+	Params  json.RawMessage  `json:"params,omitempty"`
+	Result  json.RawMessage  `json:"result,omitempty"`
+	Error   *rpcError        `json:"error,omitempty"`
+}
+
+// rpcError is a JSON-RPC 2.0 error.
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+const (
+	codeInvalidParams  = -32602
+	codeMethodNotFound = -32601
+)
+
+// rpcConn reads and writes LSP messages with Content-Length framing.
+type rpcConn struct {
+	r *textproto.Reader
+	w io.Writer
+}
+
+// newRPCConn returns a connection using the reader and writer.
+func newRPCConn(r io.Reader, w io.Writer) *rpcConn {
+	return &rpcConn{r: textproto.NewReader(bufio.NewReader(r)), w: w}
+}
+
+// read reads the next message.
+func (c *rpcConn) read() (*rpcMessage, error) {
+	header, err := c.r.ReadMIMEHeader()
+	if err != nil {
+		return nil, err
+	}
+	length, err := strconv.Atoi(header.Get("Content-Length"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid Content-Length: %q", header.Get("Content-Length"))
+	}
+	body := make([]byte, length)
+	if _, err := io.ReadFull(c.r.R, body); err != nil {
+		return nil, err
+	}
+	var msg rpcMessage
+	if err := json.Unmarshal(body, &msg); err != nil {
+		return nil, err
+	}
+	return &msg, nil
+}
+
+// write writes a message.
+func (c *rpcConn) write(msg *rpcMessage) error {
+	msg.JSONRPC = "2.0"
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(c.w, "Content-Length: %d\r\n\r\n%s", len(body), body)
+	return err
+}
+
+// notify sends a notification to the client.
+func (c *rpcConn) notify(method string, params any) error {
+	data, err := json.Marshal(params)
+	if err != nil {
+		return err
+	}
+	return c.write(&rpcMessage{Method: method, Params: data})
+}
+
+// The LSP types which are used by the server.
+// See https://microsoft.github.io/language-server-protocol/specification
+
+type lspPosition struct {
+	Line      int `json:"line"`
+	Character int `json:"character"`
+}
+
+type lspRange struct {
+	Start lspPosition `json:"start"`
+	End   lspPosition `json:"end"`
+}
+
+type lspLocation struct {
+	URI   string   `json:"uri"`
+	Range lspRange `json:"range"`
+}
+
+type lspTextDocument struct {
+	URI        string `json:"uri"`
+	LanguageID string `json:"languageId,omitempty"`
+	Version    int    `json:"version,omitempty"`
+	Text       string `json:"text,omitempty"`
+}
+
+type lspContentChange struct {
+	Range *lspRange `json:"range,omitempty"`
+	Text  string    `json:"text"`
+}
+
+type lspDidChangeParams struct {
+	TextDocument   lspTextDocument    `json:"textDocument"`
+	ContentChanges []lspContentChange `json:"contentChanges"`
+}
+
+type lspDocumentParams struct {
+	TextDocument lspTextDocument `json:"textDocument"`
+	Position     lspPosition     `json:"position"`
+	Range        lspRange        `json:"range"`
+}
+
+type lspInitializeParams struct {
+	RootURI string `json:"rootUri"`
+}
+
+type lspWorkspaceSymbolParams struct {
+	Query string `json:"query"`
+}
+
+type lspDiagnostic struct {
+	Range    lspRange `json:"range"`
+	Severity int      `json:"severity"`
+	Source   string   `json:"source"`
+	Message  string   `json:"message"`
+}
+
+type lspPublishDiagnosticsParams struct {
+	URI         string          `json:"uri"`
+	Diagnostics []lspDiagnostic `json:"diagnostics"`
+}
+
+type lspMarkupContent struct {
+	Kind  string `json:"kind"`
+	Value string `json:"value"`
+}
+
+type lspHover struct {
+	Contents lspMarkupContent `json:"contents"`
+	Range    lspRange         `json:"range"`
+}
+
+type lspTextEdit struct {
+	Range   lspRange `json:"range"`
+	NewText string   `json:"newText"`
+}
+
+type lspWorkspaceEdit struct {
+	Changes map[string][]lspTextEdit `json:"changes"`
+}
+
+type lspCodeAction struct {
+	Title string           `json:"title"`
+	Kind  string           `json:"kind"`
+	Edit  lspWorkspaceEdit `json:"edit"`
+}
+
+type lspSymbolInformation struct {
+	Name     string      `json:"name"`
+	Kind     int         `json:"kind"`
+	Location lspLocation `json:"location"`
+}
+
+type lspCompletionItem struct {
+	Label  string `json:"label"`
+	Kind   int    `json:"kind"`
+	Detail string `json:"detail,omitempty"`
+}
+
+const (
+	symbolKindString       = 15
+	completionKindProperty = 10
+)
+
+// uriToPath converts a file URI to a file path.
+func uriToPath(uri string) string {
+	u, err := url.Parse(uri)
+	if err != nil || u.Scheme != "file" {
+		return uri
+	}
+	return filepath.FromSlash(u.Path)
+}
+
+// pathToURI converts a file path to a file URI.
+func pathToURI(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+	return (&url.URL{Scheme: "file", Path: filepath.ToSlash(path)}).String()
+}
+
+// positionAt converts a byte offset in the text to an LSP position.
+// LSP characters are counted in UTF-16 code units.
+func positionAt(text string, offset int) lspPosition {
+	offset = min(offset, len(text))
+	line := strings.Count(text[:offset], "\n")
+	start := strings.LastIndexByte(text[:offset], '\n') + 1
+	var character int
+	for _, r := range text[start:offset] {
+		character += utf16.RuneLen(r)
+	}
+	return lspPosition{Line: line, Character: character}
+}
+
+// offsetAt converts an LSP position to a byte offset in the text.
+func offsetAt(text string, pos lspPosition) int {
+	offset := 0
+	for range pos.Line {
+		i := strings.IndexByte(text[offset:], '\n')
+		if i < 0 {
+			return len(text)
+		}
+		offset += i + 1
+	}
+	for character := 0; character < pos.Character && offset < len(text); {
+		r, size := utf8.DecodeRuneInString(text[offset:])
+		if r == '\n' {
+			break
+		}
+		character += utf16.RuneLen(r)
+		offset += size
+	}
+	return offset
+}
@@ -0,0 +1,299 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/icholy/todo"
+)
+
+func TestPositionAt(t *testing.T) {
+	text := "a😀b\nçd\n"
+	tests := []struct {
+		offset int
+		want   lspPosition
+	}{
+		{offset: 0, want: lspPosition{Line: 0, Character: 0}},
+		{offset: 1, want: lspPosition{Line: 0, Character: 1}},
+		// the emoji is 4 bytes and 2 UTF-16 code units
+		{offset: 5, want: lspPosition{Line: 0, Character: 3}},
+		{offset: 6, want: lspPosition{Line: 0, Character: 4}},
+		{offset: 7, want: lspPosition{Line: 1, Character: 0}},
+		{offset: 9, want: lspPosition{Line: 1, Character: 1}},
+		{offset: 10, want: lspPosition{Line: 1, Character: 2}},
+		{offset: 100, want: lspPosition{Line: 2, Character: 0}},
+	}
+	for _, tt := range tests {
+		if got := positionAt(text, tt.offset); got != tt.want {
+			t.Errorf("positionAt(%d) = %+v, want %+v", tt.offset, got, tt.want)
+		}
+	}
+}
+
+func TestOffsetAt(t *testing.T) {
+	text := "a😀b\nçd\n"
+	tests := []struct {
+		pos  lspPosition
+		want int
+	}{
+		{pos: lspPosition{Line: 0, Character: 0}, want: 0},
+		{pos: lspPosition{Line: 0, Character: 3}, want: 5},
+		{pos: lspPosition{Line: 0, Character: 4}, want: 6},
+		// past the end of the line
+		{pos: lspPosition{Line: 0, Character: 10}, want: 6},
+		{pos: lspPosition{Line: 1, Character: 1}, want: 9},
+		{pos: lspPosition{Line: 5, Character: 0}, want: len(text)},
+	}
+	for _, tt := range tests {
+		if got := offsetAt(text, tt.pos); got != tt.want {
+			t.Errorf("offsetAt(%+v) = %d, want %d", tt.pos, got, tt.want)
+		}
+	}
+}
+
+// lspClient sends messages to a server running in a goroutine.
+type lspClient struct {
+	t    *testing.T
+	conn *rpcConn
+	id   int
+	done chan error
+}
+
+// newLSPClient starts a server connected to the client.
+func newLSPClient(t *testing.T) *lspClient {
+	t.Helper()
+	serverIn, clientOut := io.Pipe()
+	clientIn, serverOut := io.Pipe()
+	s := &lspServer{
+		conn: newRPCConn(serverIn, serverOut),
+		docs: map[string]*lspDocument{},
+		keys: defaultKeys,
+	}
+	c := &lspClient{t: t, conn: newRPCConn(clientIn, clientOut), done: make(chan error, 1)}
+	go func() {
+		err := s.run()
+		serverOut.Close()
+		c.done <- err
+	}()
+	t.Cleanup(func() { clientOut.Close() })
+	return c
+}
+
+// send sends a notification, or a request if result is not nil.
+// The response to a request is decoded into result.
+func (c *lspClient) send(method string, params, result any) {
+	c.t.Helper()
+	data, err := json.Marshal(params)
+	if err != nil {
+		c.t.Fatal(err)
+	}
+	msg := &rpcMessage{Method: method, Params: data}
+	if result != nil {
+		c.id++
+		id := json.RawMessage(strings.Repeat("1", c.id))
+		msg.ID = &id
+	}
+	if err := c.conn.write(msg); err != nil {
+		c.t.Fatalf("write %s: %v", method, err)
+	}
+	if result == nil {
+		return
+	}
+	var resp struct {
+		Result json.RawMessage `json:"result"`
+		Error  *rpcError       `json:"error"`
+	}
+	c.receive(&resp)
+	if resp.Error != nil {
+		c.t.Fatalf("%s: %s", method, resp.Error.Message)
+	}
+	if resp.Result == nil {
+		c.t.Fatalf("%s: response has no result", method)
+	}
+	if err := json.Unmarshal(resp.Result, result); err != nil {
+		c.t.Fatalf("%s: %v", method, err)
+	}
+}
+
+// receive decodes the next message from the server.
+func (c *lspClient) receive(v any) {
+	c.t.Helper()
+	msg, err := c.conn.read()
+	if err != nil {
+		c.t.Fatalf("read: %v", err)
+	}
+	data, err := json.Marshal(msg)
+	if err != nil {
+		c.t.Fatal(err)
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		c.t.Fatal(err)
+	}
+}
+
+// diagnostics decodes the next publishDiagnostics notification.
+func (c *lspClient) diagnostics() []lspDiagnostic {
+	c.t.Helper()
+	var msg struct {
+		Method string                      `json:"method"`
+		Params lspPublishDiagnosticsParams `json:"params"`
+	}
+	c.receive(&msg)
+	if msg.Method != "textDocument/publishDiagnostics" {
+		c.t.Fatalf("method = %q, want publishDiagnostics", msg.Method)
+	}
+	return msg.Params.Diagnostics
+}
+
+func TestLSP(t *testing.T) {
+	c := newLSPClient(t)
+	var init map[string]any
+	c.send("initialize", lspInitializeParams{RootURI: pathToURI(t.TempDir())}, &init)
+	if _, ok := init["capabilities"]; !ok {
+		t.Fatalf("initialize = %v, want capabilities", init)
+	}
+	c.send("initialized", struct{}{}, nil)
+
+	uri := "file:///project/main.go"
+	text := "package main\n\n// TODO(owner=alice): fix 😀\n"
+	c.send("textDocument/didOpen", lspDocumentParams{
+		TextDocument: lspTextDocument{URI: uri, LanguageID: "go", Version: 1, Text: text},
+	}, nil)
+	diags := c.diagnostics()
+	want := lspRange{
+		Start: lspPosition{Line: 2, Character: 3},
+		End:   lspPosition{Line: 2, Character: 28},
+	}
+	if len(diags) != 1 || diags[0].Message != "TODO(owner=alice): fix 😀" || diags[0].Range != want {
+		t.Fatalf("diagnostics = %+v", diags)
+	}
+
+	// replace alice with bob
+	start := strings.Index(text, "alice")
+	c.send("textDocument/didChange", lspDidChangeParams{
+		TextDocument: lspTextDocument{URI: uri, Version: 2},
+		ContentChanges: []lspContentChange{{
+			Range: &lspRange{Start: positionAt(text, start), End: positionAt(text, start+len("alice"))},
+			Text:  "bob",
+		}},
+	}, nil)
+	diags = c.diagnostics()
+	want.End.Character = 26
+	if len(diags) != 1 || diags[0].Message != "TODO(owner=bob): fix 😀" || diags[0].Range != want {
+		t.Fatalf("diagnostics = %+v", diags)
+	}
+
+	var actions []lspCodeAction
+	c.send("textDocument/codeAction", lspDocumentParams{
+		TextDocument: lspTextDocument{URI: uri},
+		Range:        lspRange{Start: lspPosition{Line: 2}, End: lspPosition{Line: 2}},
+	}, &actions)
+	i := slices.IndexFunc(actions, func(a lspCodeAction) bool { return a.Title == "Stamp TODO" })
+	if i < 0 {
+		t.Fatalf("codeAction = %+v, want Stamp TODO", actions)
+	}
+	edits := actions[i].Edit.Changes[uri]
+	if len(edits) != 1 || edits[0].Range != want || !strings.HasPrefix(edits[0].NewText, "TODO(owner=bob, created=") {
+		t.Fatalf("Stamp TODO edits = %+v", edits)
+	}
+
+	// replace the whole text, and complete the next attribute key
+	text = "package main\n\n// TODO(owner=bob, ): fix 😀\n"
+	c.send("textDocument/didChange", lspDidChangeParams{
+		TextDocument:   lspTextDocument{URI: uri, Version: 3},
+		ContentChanges: []lspContentChange{{Text: text}},
+	}, nil)
+	c.diagnostics()
+	var items []lspCompletionItem
+	c.send("textDocument/completion", lspDocumentParams{
+		TextDocument: lspTextDocument{URI: uri},
+		Position:     positionAt(text, strings.Index(text, ", )")+2),
+	}, &items)
+	var labels []string
+	for _, item := range items {
+		labels = append(labels, item.Label)
+	}
+	if slices.Contains(labels, "owner") || !slices.Contains(labels, "issue") {
+		t.Fatalf("completion = %q, want keys other than owner", labels)
+	}
+
+	var result json.RawMessage
+	c.send("shutdown", nil, &result)
+	if string(result) != "null" {
+		t.Fatalf("shutdown = %s, want null", result)
+	}
+	c.send("exit", nil, nil)
+	if err := <-c.done; err != nil {
+		t.Fatalf("run() error = %v", err)
+	}
+}
+
+func TestLSPSymbols(t *testing.T) {
+	root := t.TempDir()
+	path := filepath.Join(root, "main.go")
+	write := func(text string, modTime time.Time) {
+		t.Helper()
+		if err := os.WriteFile(path, []byte(text), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(path, modTime, modTime); err != nil {
+			t.Fatal(err)
+		}
+	}
+	s := &lspServer{docs: map[string]*lspDocument{}, root: root}
+	names := func(query string) []string {
+		var names []string
+		for _, sym := range s.symbols(query).([]lspSymbolInformation) {
+			names = append(names, sym.Name)
+		}
+		return names
+	}
+	modTime := time.Date(2025, 3, 9, 12, 0, 0, 0, time.UTC)
+	write("package main\n\n// TODO: first\n// TODO: second\n", modTime)
+	if got, want := names("first"), []string{"TODO: first"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("symbols() = %q, want %q", got, want)
+	}
+	// the cached symbols are used while the file is unchanged
+	s.files[path].symbols[0].Name = "TODO: cached"
+	if got, want := names("cached"), []string{"TODO: cached"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("symbols() = %q, want %q", got, want)
+	}
+	write("package main\n\n// TODO: third\n", modTime.Add(time.Second))
+	if got, want := names(""), []string{"TODO: third"}; !reflect.DeepEqual(got, want) {
+		t.Fatalf("symbols() = %q, want %q", got, want)
+	}
+	if err := os.Remove(path); err != nil {
+		t.Fatal(err)
+	}
+	if got := names(""); len(got) != 0 || len(s.files) != 0 {
+		t.Fatalf("symbols() = %q after the file was removed", got)
+	}
+}
+
+func TestLSPHover(t *testing.T) {
+	text := "package main\n\n// TODO(note=\"a|b\"): fix\n"
+	todos, err := todo.Parse("main.go", []byte(text))
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &lspServer{}
+	doc := &lspDocument{uri: "file:///main.go", text: text, todos: todos}
+	hover, ok := s.hover(doc, lspDocumentParams{Position: lspPosition{Line: 2}}).(lspHover)
+	if !ok {
+		t.Fatal("hover() returned no hover")
+	}
+	want := "**TODO**: fix\n\n| Attribute | Value |\n| --- | --- |\n| note | a\\|b |\n"
+	if hover.Contents.Value != want {
+		t.Errorf("hover() = %q, want %q", hover.Contents.Value, want)
+	}
+	if got := s.hover(doc, lspDocumentParams{Position: lspPosition{Line: 0}}); got != nil {
+		t.Errorf("hover() = %v, want nil", got)
+	}
+}
@@ -1,27 +1,185 @@
 package main
 
 import (
+	"cmp"
 	"flag"
 	"fmt"
 	"log"
 	"os"
+	"strings"
+	"text/template"
+	"time"
 
 	"github.com/icholy/todo"
 )
 
+// commands are the sub-commands. Any other arguments are treated as files to scan.
+var commands = map[string]func(args []string) error{
+	"baseline": baselineCommand,
+	"check":    checkCommand,
+	"diff":     diffCommand,
+	"edit":     editCommand,
+	"fmt":      fmtCommand,
+	"history":  historyCommand,
+	"lsp":      lspCommand,
+	"report":   reportCommand,
+	"serve":    serveCommand,
+	"stamp":    stampCommand,
+	"stats":    statsCommand,
+	"watch":    watchCommand,
+}
+
 func main() {
+	if len(os.Args) > 1 {
+		if command, ok := commands[os.Args[1]]; ok {
+			if err := command(os.Args[2:]); err != nil {
+				log.Fatal(err)
+			}
+			return
+		}
+	}
+	flag.Func("lang", "override the language for a glob pattern (`pattern=name`)", func(s string) error {
+		pattern, name, ok := strings.Cut(s, "=")
+		if !ok {
+			return fmt.Errorf("expected pattern=name: %q", s)
+		}
+		return todo.OverrideLanguage(pattern, name)
+	})
+	keywordsFlag(flag.CommandLine)
+	where := whereFlag(flag.CommandLine)
+	blame := flag.Bool("blame", false, "show the commit which introduced each TODO")
+	rev := flag.String("rev", "", "scan a git revision instead of the working tree, arguments are pathspecs")
+	groupBy := groupByFlag(flag.CommandLine)
+	parseTemplate := templateFlags(flag.CommandLine)
+	format := formatFlag(flag.CommandLine)
+	sortBy := flag.String("sort", "", "comma separated list of `keys` to sort by: path, line, deadline, priority or created, prefix a key with - to reverse it")
 	flag.Parse()
-	for _, filename := range flag.Args() {
-		source, err := os.ReadFile(filename)
-		if err != nil {
+	tmpl, err := parseTemplate()
+	if err != nil {
+		log.Fatal(err)
+	}
+	var todos []todo.Todo
+	if *rev != "" {
+		if *blame {
+			log.Fatal("-blame cannot be used with -rev")
+		}
+		if todos, err = todo.ParseRevision(".", *rev, flag.Args()...); err != nil {
 			log.Fatal(err)
 		}
-		todos, err := todo.Parse(filename, source)
-		if err != nil {
+	} else {
+		if todos, err = parseFiles(flag.Args()); err != nil {
+			log.Fatal(err)
+		}
+	}
+	todos = where.Apply(todos)
+	if *blame {
+		if err := todo.Blame(todos); err != nil {
+			log.Fatal(err)
+		}
+	}
+	if *sortBy != "" {
+		if err := todo.SortBy(todos, strings.Split(*sortBy, ",")...); err != nil {
+			log.Fatal(err)
+		}
+	}
+	if *format != "" {
+		if *groupBy != "" || tmpl != nil {
+			log.Fatal("-format cannot be used with -group-by or -template")
+		}
+		if err := writeTodos(os.Stdout, *format, todos, time.Now()); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+	if *groupBy == "" {
+		if err := printTodos(todos, tmpl); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+	for i, g := range todo.GroupBy(todos, *groupBy) {
+		if i > 0 {
+			fmt.Println()
+		}
+		fmt.Printf("%s:\n", groupName(g))
+		if err := printTodos(g.Todos, tmpl); err != nil {
 			log.Fatal(err)
 		}
-		for _, t := range todos {
+	}
+}
+
+// printTodos prints one TODO per line, or executes the template for each TODO.
+func printTodos(todos []todo.Todo, tmpl *template.Template) error {
+	for _, t := range todos {
+		if tmpl != nil {
+			if err := tmpl.Execute(os.Stdout, t); err != nil {
+				return err
+			}
+			continue
+		}
+		if c := t.Commit; c != nil {
+			fmt.Printf("%s %s (%.8s %s %s)\n", t.Location, t, c.Hash, c.Author, c.AuthorTime.Format(time.DateOnly))
+		} else {
 			fmt.Printf("%s %s\n", t.Location, t)
 		}
 	}
+	return nil
+}
+
+// keywordsFlag adds a -keywords flag which sets the keywords of the default registry.
+func keywordsFlag(fset *flag.FlagSet) {
+	fset.Func("keywords", "comma separated list of keywords (default TODO)", func(s string) error {
+		return todo.DefaultRegistry.SetKeywords(strings.Split(s, ",")...)
+	})
+}
+
+// whereFlag adds a -where flag which filters the TODOs.
+// The returned filter matches every TODO if the flag isn't set.
+func whereFlag(fset *flag.FlagSet) *todo.Filter {
+	var where todo.Filter
+	fset.Func("where", "only include the TODOs which match the filter `expression`", func(s string) error {
+		f, err := todo.ParseFilter(s)
+		if err != nil {
+			return err
+		}
+		where = *f
+		return nil
+	})
+	return &where
+}
+
+// groupByFlag adds a -group-by flag for todo.GroupBy.
+func groupByFlag(fset *flag.FlagSet) *string {
+	return fset.String("group-by", "", "group the TODOs by file, dir, keyword or an attribute `key`")
+}
+
+// groupName returns the name of the group, or (none) for the TODOs without the key.
+func groupName(g todo.Group) string {
+	return cmp.Or(g.Name, "(none)")
+}
+
+// parseFiles parses the TODOs in each of the files.
+func parseFiles(filenames []string) ([]todo.Todo, error) {
+	var todos []todo.Todo
+	for _, filename := range filenames {
+		source, err := os.ReadFile(filename)
+		if err != nil {
+			return nil, err
+		}
+		parsed, err := todo.Parse(filename, source)
+		if err != nil {
+			return nil, err
+		}
+		todos = append(todos, parsed...)
+	}
+	return todos, nil
+}
+
+// writeFile replaces the contents of an existing file, keeping its permissions.
+func writeFile(filename string, data []byte) error {
+	info, err := os.Stat(filename)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filename, data, info.Mode().Perm())
 }
@@ -0,0 +1,146 @@
+package main
+
+import (
+	"cmp"
+	"flag"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf16"
+
+	"github.com/icholy/todo"
+)
+
+// outputFormats write the TODOs in the formats understood by editors and CI tools.
+// The severity of each TODO is relative to now.
+var outputFormats = map[string]func(w io.Writer, todos []todo.Todo, now time.Time) error{
+	"vimgrep":    writeVimgrep,
+	"emacs":      writeEmacs,
+	"vscode":     writeVSCode,
+	"github":     writeGitHub,
+	"gitlab":     writeGitLab,
+	"checkstyle": writeCheckstyle,
+	"junit":      writeJUnit,
+}
+
+// formatFlag adds a -format flag for the output formats.
+func formatFlag(fset *flag.FlagSet) *string {
+	names := slices.Sorted(maps.Keys(outputFormats))
+	return fset.String("format", "", "print the TODOs in an output `format`: "+strings.Join(names, ", "))
+}
+
+// writeTodos writes the TODOs in the named output format.
+func writeTodos(w io.Writer, format string, todos []todo.Todo, now time.Time) error {
+	write, ok := outputFormats[format]
+	if !ok {
+		return fmt.Errorf("unknown output format: %q", format)
+	}
+	return write(w, todos, now)
+}
+
+// column returns the column of the TODO, or 1 if it's not known.
+// The column is a byte offset, which is what vim expects.
+func column(t todo.Todo) int {
+	return max(t.Location.Column, 1)
+}
+
+// lineReader reads the lines of the TODOs' files, so the byte columns
+// can be converted to the columns used by other editors.
+// Each file is only read once.
+type lineReader map[string][]string
+
+// prefix returns the text before the TODO on its line. It reports false if
+// the file can't be read, or if it has changed since the TODO was parsed.
+func (r lineReader) prefix(t todo.Todo) (string, bool) {
+	lines, ok := r[t.Location.File]
+	if !ok {
+		if data, err := os.ReadFile(t.Location.File); err == nil {
+			lines = strings.Split(string(data), "\n")
+		}
+		r[t.Location.File] = lines
+	}
+	i, col := t.Location.Line-1, t.Location.Column-1
+	if i < 0 || i >= len(lines) || col < 0 || col > len(lines[i]) {
+		return "", false
+	}
+	if !strings.HasPrefix(lines[i][col:], cmp.Or(t.Keyword, "TODO")) {
+		return "", false
+	}
+	return lines[i][:col], true
+}
+
+// screenColumn returns the column of the TODO counted in characters, with tab
+// stops every 8 columns, as in the GNU error format which emacs expects.
+func (r lineReader) screenColumn(t todo.Todo) int {
+	prefix, ok := r.prefix(t)
+	if !ok {
+		return column(t)
+	}
+	n := 0
+	for _, c := range prefix {
+		if c == '\t' {
+			n += 8 - n%8
+		} else {
+			n++
+		}
+	}
+	return n + 1
+}
+
+// utf16Column returns the column of the TODO counted in UTF-16 code units,
+// which is what VS Code expects.
+func (r lineReader) utf16Column(t todo.Todo) int {
+	prefix, ok := r.prefix(t)
+	if !ok {
+		return column(t)
+	}
+	n := 0
+	for _, c := range prefix {
+		n += utf16.RuneLen(c)
+	}
+	return n + 1
+}
+
+// writeVimgrep writes file:line:col:text lines, like vim's grepformat.
+// The column is a byte offset.
+func writeVimgrep(w io.Writer, todos []todo.Todo, now time.Time) error {
+	for _, t := range todos {
+		if _, err := fmt.Fprintf(w, "%s:%d:%d:%s\n", t.Location.File, t.Location.Line, column(t), t); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeEmacs writes file:line:col: text lines, which compilation-mode recognizes.
+// The column is a screen column, with tab stops every 8 columns.
+func writeEmacs(w io.Writer, todos []todo.Todo, now time.Time) error {
+	lines := lineReader{}
+	for _, t := range todos {
+		if _, err := fmt.Fprintf(w, "%s:%d:%d: %s\n", t.Location.File, t.Location.Line, lines.screenColumn(t), t); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// writeVSCode writes file:line:col: severity: text lines, which can be matched by a
+// problem matcher. Hints are written as info, since problem matchers don't support them.
+// The column is counted in UTF-16 code units.
+func writeVSCode(w io.Writer, todos []todo.Todo, now time.Time) error {
+	lines := lineReader{}
+	for _, t := range todos {
+		severity := t.Severity(now)
+		if severity == todo.SeverityHint {
+			severity = todo.SeverityInfo
+		}
+		if _, err := fmt.Fprintf(w, "%s:%d:%d: %s: %s\n", t.Location.File, t.Location.Line, lines.utf16Column(t), severity, t); err != nil {
+			return err
+		}
+	}
+	return nil
+}
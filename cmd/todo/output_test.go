@@ -0,0 +1,84 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/icholy/todo"
+)
+
+// parseTestFile writes the source to a temporary file and parses it.
+func parseTestFile(t *testing.T, name, source string) (string, []todo.Todo) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(source), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	todos, err := todo.Parse(path, []byte(source))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return path, todos
+}
+
+func TestWriteEditorFormats(t *testing.T) {
+	path, todos := parseTestFile(t, "main.go", "package main\n\n"+
+		"// TODO: first\n"+
+		"func main() {\n"+
+		"\t\tx := 1 // TODO(owner=alice): tabs\n"+
+		"\ts := \"😀é\" // TODO(priority=1): unicode\n"+
+		"}\n",
+	)
+	tests := []struct {
+		format string
+		want   string
+	}{
+		{
+			format: "vimgrep",
+			want: "FILE:3:4:TODO: first\n" +
+				"FILE:5:13:TODO(owner=alice): tabs\n" +
+				"FILE:6:19:TODO(priority=1): unicode\n",
+		},
+		{
+			format: "emacs",
+			want: "FILE:3:4: TODO: first\n" +
+				"FILE:5:27: TODO(owner=alice): tabs\n" +
+				"FILE:6:22: TODO(priority=1): unicode\n",
+		},
+		{
+			format: "vscode",
+			want: "FILE:3:4: info: TODO: first\n" +
+				"FILE:5:13: info: TODO(owner=alice): tabs\n" +
+				"FILE:6:16: warning: TODO(priority=1): unicode\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			var b strings.Builder
+			if err := writeTodos(&b, tt.format, todos, time.Now()); err != nil {
+				t.Fatal(err)
+			}
+			if got := strings.ReplaceAll(b.String(), path, "FILE"); got != tt.want {
+				t.Errorf("writeTodos(%q) = %q, want %q", tt.format, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWriteEditorFormatsChanged(t *testing.T) {
+	// byte columns are used when the file no longer matches the TODOs
+	path, todos := parseTestFile(t, "main.go", "package main\n\n\t// TODO: moved\n")
+	if err := os.WriteFile(path, []byte("package main\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	var b strings.Builder
+	if err := writeTodos(&b, "emacs", todos, time.Now()); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := b.String(), path+":3:5: TODO: moved\n"; got != want {
+		t.Errorf("writeTodos() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,193 @@
+package main
+
+import (
+	"bytes"
+	_ "embed"
+	"flag"
+	"fmt"
+	htmltemplate "html/template"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+	"time"
+	"unicode"
+
+	"github.com/icholy/todo"
+)
+
+//go:embed report_markdown.tmpl
+var reportMarkdown string
+
+//go:embed report_html.tmpl
+var reportHTML string
+
+var reportFuncs = template.FuncMap{
+	// cell escapes text for a markdown table cell.
+	"cell": func(s string) string {
+		return strings.NewReplacer("|", `\|`, "\n", " ").Replace(s)
+	},
+}
+
+var (
+	reportMarkdownTemplate = template.Must(template.New("markdown").Funcs(reportFuncs).Parse(reportMarkdown))
+	reportHTMLTemplate     = htmltemplate.Must(htmltemplate.New("html").Parse(reportHTML))
+)
+
+// reportCommand writes a markdown or HTML document listing the TODOs in each group.
+func reportCommand(args []string) error {
+	fset := flag.NewFlagSet("report", flag.ExitOnError)
+	fset.Usage = func() {
+		fmt.Fprintln(fset.Output(), "usage: todo report [flags] [files...]")
+		fset.PrintDefaults()
+	}
+	format := fset.String("format", "markdown", "the report format: markdown or html")
+	groupBy := fset.String("group-by", "dir", "group the TODOs by file, dir, keyword or an attribute `key`")
+	output := fset.String("o", "", "write the report to the `file`, links are relative to its directory")
+	keywordsFlag(fset)
+	where := whereFlag(fset)
+	fset.Parse(args)
+	todos, err := parseFiles(fset.Args())
+	if err != nil {
+		return err
+	}
+	todos = where.Apply(todos)
+	if err := todo.SortBy(todos, "path"); err != nil {
+		return err
+	}
+	base := "."
+	if *output != "" {
+		base = filepath.Dir(*output)
+	}
+	data, err := newReport(todos, *groupBy, base, time.Now())
+	if err != nil {
+		return err
+	}
+	var buf bytes.Buffer
+	switch *format {
+	case "markdown", "md":
+		err = reportMarkdownTemplate.Execute(&buf, data)
+	case "html":
+		err = reportHTMLTemplate.Execute(&buf, data)
+	default:
+		return fmt.Errorf("unknown report format: %q", *format)
+	}
+	if err != nil {
+		return err
+	}
+	if *output == "" {
+		_, err := os.Stdout.Write(buf.Bytes())
+		return err
+	}
+	return os.WriteFile(*output, buf.Bytes(), 0o644)
+}
+
+// report is the data for the report templates.
+// It doesn't include the time it was generated, so that
+// reports which are committed only change with the TODOs.
+type report struct {
+	GroupBy string
+	Total   int
+	Overdue int
+	Groups  []reportGroup
+}
+
+// reportGroup is a group of TODOs in the report.
+type reportGroup struct {
+	Name    string
+	Anchor  string
+	Overdue int
+	Todos   []reportTodo
+}
+
+// reportTodo is a TODO in the report.
+type reportTodo struct {
+	Location    string
+	Link        string
+	Keyword     string
+	Description string
+	Attributes  []todo.Attribute
+	Deadline    string
+	Overdue     bool
+}
+
+// newReport groups the TODOs for the report.
+// The links are relative to the base directory.
+func newReport(todos []todo.Todo, groupBy, base string, now time.Time) (report, error) {
+	r := report{GroupBy: groupBy, Total: len(todos)}
+	// the report's title is also a heading
+	anchors := map[string]int{"todo": 1}
+	for _, g := range todo.GroupBy(todos, groupBy) {
+		group := reportGroup{Name: groupName(g)}
+		if group.Name == "." {
+			group.Name = "(root)"
+		}
+		group.Anchor = headingAnchor(group.Name, anchors)
+		for _, t := range g.Todos {
+			link, err := reportLink(base, t.Location)
+			if err != nil {
+				return report{}, err
+			}
+			rt := reportTodo{
+				Location:    t.Location.String(),
+				Link:        link,
+				Keyword:     keyword(t),
+				Description: t.Description,
+				Attributes:  t.Attributes,
+				Overdue:     t.Overdue(now),
+			}
+			if d, ok := t.Deadline(); ok {
+				rt.Deadline = d.Format(time.DateOnly)
+			}
+			if rt.Overdue {
+				group.Overdue++
+				r.Overdue++
+			}
+			group.Todos = append(group.Todos, rt)
+		}
+		r.Groups = append(r.Groups, group)
+	}
+	return r, nil
+}
+
+// reportLink returns an escaped link to the line, relative to the base directory.
+// Parentheses are escaped too, so the link can be used in markdown.
+func reportLink(base string, loc todo.Location) (string, error) {
+	absBase, err := filepath.Abs(base)
+	if err != nil {
+		return "", err
+	}
+	absFile, err := filepath.Abs(loc.File)
+	if err != nil {
+		return "", err
+	}
+	rel, err := filepath.Rel(absBase, absFile)
+	if err != nil {
+		return "", err
+	}
+	u := url.URL{Path: filepath.ToSlash(rel), Fragment: fmt.Sprintf("L%d", loc.Line)}
+	return strings.NewReplacer("(", "%28", ")", "%29").Replace(u.String()), nil
+}
+
+// headingAnchor returns the id which GitHub generates for a heading.
+// Repeated ids get a -1, -2, ... suffix, and the counts are kept in used.
+func headingAnchor(heading string, used map[string]int) string {
+	anchor := strings.Map(func(r rune) rune {
+		switch {
+		case r == ' ':
+			return '-'
+		case r == '-' || r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r):
+			return unicode.ToLower(r)
+		default:
+			return -1
+		}
+	}, heading)
+	unique := anchor
+	for used[unique] > 0 {
+		unique = fmt.Sprintf("%s-%d", anchor, used[anchor])
+		used[anchor]++
+	}
+	used[unique]++
+	return unique
+}
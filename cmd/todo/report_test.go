@@ -0,0 +1,106 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/icholy/todo"
+)
+
+func TestReportGolden(t *testing.T) {
+	todos := []todo.Todo{
+		{
+			Location:    todo.Location{File: "main.go", Line: 3},
+			Keyword:     "TODO",
+			Description: "a|b",
+			Attributes:  []todo.Attribute{{Key: "owner", Value: "alice"}, {Key: "deadline", Value: "2025-01-01"}},
+		},
+		{
+			Location:    todo.Location{File: "pkg/my file (1).go", Line: 10},
+			Keyword:     "FIXME",
+			Description: "<escape> & link",
+		},
+	}
+	now := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	data, err := newReport(todos, "keyword", ".", now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		golden  string
+		execute func(b *strings.Builder) error
+	}{
+		{
+			golden:  "report.md",
+			execute: func(b *strings.Builder) error { return reportMarkdownTemplate.Execute(b, data) },
+		},
+		{
+			golden:  "report.html",
+			execute: func(b *strings.Builder) error { return reportHTMLTemplate.Execute(b, data) },
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.golden, func(t *testing.T) {
+			want, err := os.ReadFile(filepath.Join("testdata", tt.golden))
+			if err != nil {
+				t.Fatal(err)
+			}
+			var b strings.Builder
+			if err := tt.execute(&b); err != nil {
+				t.Fatal(err)
+			}
+			if got := b.String(); got != string(want) {
+				t.Errorf("report = %s, want %s", got, want)
+			}
+		})
+	}
+}
+
+func TestHeadingAnchor(t *testing.T) {
+	used := map[string]int{"todo": 1}
+	tests := []struct {
+		heading string
+		want    string
+	}{
+		{heading: "pkg/api", want: "pkgapi"},
+		{heading: "Hello, World!", want: "hello-world"},
+		{heading: "snake_case-kebab", want: "snake_case-kebab"},
+		{heading: "Équipe 2", want: "équipe-2"},
+		{heading: "TODO", want: "todo-1"},
+		{heading: "todo", want: "todo-2"},
+		{heading: "pkg.api", want: "pkgapi-1"},
+		{heading: "pkgapi-1", want: "pkgapi-1-1"},
+		{heading: "pkg api", want: "pkg-api"},
+	}
+	for _, tt := range tests {
+		if got := headingAnchor(tt.heading, used); got != tt.want {
+			t.Errorf("headingAnchor(%q) = %q, want %q", tt.heading, got, tt.want)
+		}
+	}
+}
+
+func TestReportLink(t *testing.T) {
+	tests := []struct {
+		base string
+		file string
+		want string
+	}{
+		{base: ".", file: "main.go", want: "main.go#L7"},
+		{base: "docs", file: "pkg/main.go", want: "../pkg/main.go#L7"},
+		{base: ".", file: "my file (1).go", want: "my%20file%20%281%29.go#L7"},
+		{base: ".", file: "a#b?.go", want: "a%23b%3F.go#L7"},
+		{base: ".", file: "c:d.go", want: "./c:d.go#L7"},
+	}
+	for _, tt := range tests {
+		got, err := reportLink(tt.base, todo.Location{File: tt.file, Line: 7})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != tt.want {
+			t.Errorf("reportLink(%q, %q) = %q, want %q", tt.base, tt.file, got, tt.want)
+		}
+	}
+}
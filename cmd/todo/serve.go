@@ -0,0 +1,331 @@
+package main
+
+import (
+	"bufio"
+	"cmp"
+	"context"
+	_ "embed"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"html/template"
+	"log"
+	"maps"
+	"net/http"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/icholy/todo"
+)
+
+//go:embed serve.html
+var serveHTML string
+
+var serveTemplate = template.Must(template.New("serve").Parse(serveHTML))
+
+// serveCommand serves a web UI and JSON API for browsing the TODOs in a directory.
+func serveCommand(args []string) error {
+	fset := flag.NewFlagSet("serve", flag.ExitOnError)
+	fset.Usage = func() {
+		fmt.Fprintln(fset.Output(), "usage: todo serve [flags] [dir]")
+		fset.PrintDefaults()
+	}
+	addr := fset.String("addr", "localhost:8080", "the address to listen on")
+	watch := fset.Bool("watch", false, "rescan files as they change")
+	keywordsFlag(fset)
+	fset.Parse(args)
+	root := "."
+	if fset.NArg() > 0 {
+		root = fset.Arg(0)
+	}
+	w := &watcher{
+		root:  root,
+		files: map[string]watchedFile{},
+		print: func(todo.Change) error { return nil },
+	}
+	if err := w.scan(false); err != nil {
+		return err
+	}
+	if *watch {
+		go func() {
+			if err := w.notify(context.Background(), 200*time.Millisecond); err != nil {
+				log.Printf("watch: %v", err)
+			}
+		}()
+	}
+	s := &server{watcher: w}
+	log.Printf("serving %s on http://%s", root, *addr)
+	return http.ListenAndServe(*addr, s.handler())
+}
+
+// server handles the web UI and JSON API requests.
+type server struct {
+	watcher *watcher
+}
+
+// handler returns the handler for the web UI and JSON API routes.
+func (s *server) handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /{$}", s.index)
+	mux.HandleFunc("GET /file", s.file)
+	mux.HandleFunc("GET /api/todos", s.apiTodos)
+	mux.HandleFunc("POST /api/rescan", s.apiRescan)
+	return mux
+}
+
+// serveTodo is the JSON representation of a TODO.
+type serveTodo struct {
+	File        string            `json:"file"`
+	Line        int               `json:"line"`
+	Keyword     string            `json:"keyword"`
+	Description string            `json:"description"`
+	Attributes  map[string]string `json:"attributes"`
+	Text        string            `json:"text"`
+	Severity    string            `json:"severity"`
+	Deadline    *time.Time        `json:"deadline,omitempty"`
+}
+
+// newServeTodo converts a TODO to its JSON representation.
+func newServeTodo(t todo.Todo, now time.Time) serveTodo {
+	st := serveTodo{
+		File:        filepath.ToSlash(t.Location.File),
+		Line:        t.Location.Line,
+		Keyword:     cmp.Or(t.Keyword, "TODO"),
+		Description: t.Description,
+		Attributes:  map[string]string{},
+		Text:        t.String(),
+		Severity:    t.Severity(now).String(),
+	}
+	for _, a := range t.Attributes {
+		st.Attributes[a.Key] = a.Value
+	}
+	if d, ok := t.Deadline(); ok {
+		st.Deadline = &d
+	}
+	return st
+}
+
+// serveGroup is a named group of TODOs.
+type serveGroup struct {
+	Name  string      `json:"name"`
+	Todos []serveTodo `json:"todos"`
+}
+
+// serveQuery are the filter and grouping parameters.
+type serveQuery struct {
+	Owner    string
+	Keyword  string
+	Attr     string
+	Dir      string
+	Deadline string
+	Where    string
+	Group    string
+
+	filter *todo.Filter
+}
+
+// parseServeQuery reads the query parameters from the request.
+// The attr parameter is a key, or a key=value pair, and the where
+// parameter is a filter expression.
+func parseServeQuery(r *http.Request) (serveQuery, error) {
+	q := r.URL.Query()
+	sq := serveQuery{
+		Owner:    q.Get("owner"),
+		Keyword:  q.Get("keyword"),
+		Attr:     q.Get("attr"),
+		Dir:      q.Get("dir"),
+		Deadline: q.Get("deadline"),
+		Where:    q.Get("where"),
+		Group:    q.Get("group"),
+	}
+	if sq.Where != "" {
+		f, err := todo.ParseFilter(sq.Where)
+		if err != nil {
+			return sq, err
+		}
+		sq.filter = f
+	}
+	return sq, nil
+}
+
+// match reports whether the TODO matches the filters.
+func (q serveQuery) match(t todo.Todo, now time.Time) bool {
+	if q.Owner != "" {
+		if owner, _ := t.Attribute("owner"); owner != q.Owner {
+			return false
+		}
+	}
+	if q.Keyword != "" && cmp.Or(t.Keyword, "TODO") != q.Keyword {
+		return false
+	}
+	if q.Attr != "" {
+		key, value, hasValue := strings.Cut(q.Attr, "=")
+		v, ok := t.Attribute(key)
+		if !ok || (hasValue && v != value) {
+			return false
+		}
+	}
+	if q.Dir != "" {
+		dir := filepath.ToSlash(filepath.Clean(q.Dir))
+		file := filepath.ToSlash(filepath.Clean(t.Location.File))
+		if dir != "." && !strings.HasPrefix(file, dir+"/") {
+			return false
+		}
+	}
+	if q.Deadline != "" && deadlineGroup(t, now) != q.Deadline {
+		return false
+	}
+	return q.filter.Match(t)
+}
+
+// groupName returns the name of the TODO's group.
+// Groups are owner, keyword, dir, deadline, or attr:<key>.
+func (q serveQuery) groupName(t todo.Todo, now time.Time) string {
+	switch q.Group {
+	case "owner":
+		owner, _ := t.Attribute("owner")
+		return cmp.Or(owner, "(none)")
+	case "keyword":
+		return cmp.Or(t.Keyword, "TODO")
+	case "dir":
+		return filepath.ToSlash(filepath.Dir(t.Location.File))
+	case "deadline":
+		return deadlineGroup(t, now)
+	default:
+		if key, ok := strings.CutPrefix(q.Group, "attr:"); ok {
+			value, _ := t.Attribute(key)
+			return cmp.Or(value, "(none)")
+		}
+		return "all"
+	}
+}
+
+// deadlineGroup returns overdue, week, later, or none based on the TODO's deadline.
+func deadlineGroup(t todo.Todo, now time.Time) string {
+	d, ok := t.Deadline()
+	switch {
+	case !ok:
+		return "none"
+	case d.Before(now):
+		return "overdue"
+	case d.Before(now.AddDate(0, 0, 7)):
+		return "week"
+	default:
+		return "later"
+	}
+}
+
+// groups filters and groups the TODOs. The groups are sorted by name.
+func (s *server) groups(q serveQuery) []serveGroup {
+	now := time.Now()
+	byName := map[string][]serveTodo{}
+	for _, t := range s.watcher.todos() {
+		if !q.match(t, now) {
+			continue
+		}
+		name := q.groupName(t, now)
+		byName[name] = append(byName[name], newServeTodo(t, now))
+	}
+	groups := []serveGroup{}
+	for _, name := range slices.Sorted(maps.Keys(byName)) {
+		groups = append(groups, serveGroup{Name: name, Todos: byName[name]})
+	}
+	return groups
+}
+
+// index renders the web UI.
+func (s *server) index(w http.ResponseWriter, r *http.Request) {
+	q, err := parseServeQuery(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	err = serveTemplate.ExecuteTemplate(w, "index", map[string]any{
+		"Query":  q,
+		"Groups": s.groups(q),
+	})
+	if err != nil {
+		log.Printf("render: %v", err)
+	}
+}
+
+// contextLine is a line of source shown around a TODO.
+type contextLine struct {
+	Number int
+	Text   string
+	Todo   bool
+}
+
+// file renders the lines around a TODO, with the TODO line highlighted.
+// Only the files which have been scanned can be viewed.
+func (s *server) file(w http.ResponseWriter, r *http.Request) {
+	path := filepath.FromSlash(r.URL.Query().Get("path"))
+	line, _ := strconv.Atoi(r.URL.Query().Get("line"))
+	if !s.watcher.hasFile(path) {
+		http.NotFound(w, r)
+		return
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	defer f.Close()
+	const around = 10
+	var lines []contextLine
+	sc := bufio.NewScanner(f)
+	for n := 1; sc.Scan(); n++ {
+		if n < line-around {
+			continue
+		}
+		if n > line+around {
+			break
+		}
+		lines = append(lines, contextLine{Number: n, Text: sc.Text(), Todo: n == line})
+	}
+	err = serveTemplate.ExecuteTemplate(w, "file", map[string]any{
+		"Path":  filepath.ToSlash(path),
+		"Line":  line,
+		"Lines": lines,
+	})
+	if err != nil {
+		log.Printf("render: %v", err)
+	}
+}
+
+// apiTodos returns the filtered and grouped TODOs as JSON.
+func (s *server) apiTodos(w http.ResponseWriter, r *http.Request) {
+	q, err := parseServeQuery(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	writeJSON(w, s.groups(q))
+}
+
+// apiRescan scans the directory for changes.
+func (s *server) apiRescan(w http.ResponseWriter, r *http.Request) {
+	if err := s.watcher.scan(false); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if r.FormValue("redirect") != "" {
+		http.Redirect(w, r, "/", http.StatusSeeOther)
+		return
+	}
+	writeJSON(w, map[string]int{"todos": len(s.watcher.todos())})
+}
+
+// writeJSON writes the value as a JSON response.
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		log.Printf("encode: %v", err)
+	}
+}
@@ -0,0 +1,165 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/icholy/todo"
+)
+
+// newTestServer returns a server for a scanned directory containing the files.
+func newTestServer(t *testing.T, files map[string]string) (http.Handler, string) {
+	t.Helper()
+	root := t.TempDir()
+	for name, content := range files {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	w := &watcher{
+		root:  root,
+		files: map[string]watchedFile{},
+		print: func(todo.Change) error { return nil },
+	}
+	if err := w.scan(false); err != nil {
+		t.Fatal(err)
+	}
+	s := &server{watcher: w}
+	return s.handler(), root
+}
+
+// get performs a request and returns the response recorder.
+func get(h http.Handler, method, target string) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(method, target, nil))
+	return rec
+}
+
+func TestServeAPI(t *testing.T) {
+	h, root := newTestServer(t, map[string]string{
+		"a/one.go":   "package a\n\n// TODO(owner=alice, deadline=2000-01-01): overdue\n",
+		"b/two.txt":  "TODO(owner=bob, priority=1): later\n",
+		"b/three.py": "# TODO: unowned\n",
+	})
+	tests := []struct {
+		query string
+		want  map[string][]string
+	}{
+		{
+			query: "",
+			want:  map[string][]string{"all": {"overdue", "unowned", "later"}},
+		},
+		{
+			query: "owner=alice",
+			want:  map[string][]string{"all": {"overdue"}},
+		},
+		{
+			query: "attr=priority%3D1",
+			want:  map[string][]string{"all": {"later"}},
+		},
+		{
+			query: "group=owner&dir=" + url.QueryEscape(filepath.Join(root, "b")),
+			want:  map[string][]string{"(none)": {"unowned"}, "bob": {"later"}},
+		},
+		{
+			query: "group=deadline",
+			want:  map[string][]string{"none": {"unowned", "later"}, "overdue": {"overdue"}},
+		},
+		{
+			query: "where=" + url.QueryEscape("owner != alice") + "&group=attr:priority",
+			want:  map[string][]string{"(none)": {"unowned"}, "1": {"later"}},
+		},
+		{
+			query: "deadline=week",
+			want:  map[string][]string{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			rec := get(h, "GET", "/api/todos?"+tt.query)
+			if rec.Code != http.StatusOK {
+				t.Fatalf("status = %d, body = %q", rec.Code, rec.Body)
+			}
+			var groups []serveGroup
+			if err := json.Unmarshal(rec.Body.Bytes(), &groups); err != nil {
+				t.Fatal(err)
+			}
+			got := map[string][]string{}
+			for _, g := range groups {
+				for _, st := range g.Todos {
+					got[g.Name] = append(got[g.Name], st.Description)
+				}
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("groups = %q, want %q", got, tt.want)
+			}
+		})
+	}
+	if rec := get(h, "GET", "/api/todos?where=("); rec.Code != http.StatusBadRequest {
+		t.Errorf("invalid where status = %d, want %d", rec.Code, http.StatusBadRequest)
+	}
+	rec := get(h, "POST", "/api/rescan")
+	var rescan map[string]int
+	if err := json.Unmarshal(rec.Body.Bytes(), &rescan); err != nil || rescan["todos"] != 3 {
+		t.Errorf("rescan = %d %q", rec.Code, rec.Body)
+	}
+}
+
+func TestServeIndex(t *testing.T) {
+	h, _ := newTestServer(t, map[string]string{
+		"one.go": "package main\n\n// TODO(owner=alice): first <b>\n",
+		"two.go": "package main\n\n// TODO(owner=bob): second\n",
+	})
+	rec := get(h, "GET", "/?owner=alice")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, body = %q", rec.Code, rec.Body)
+	}
+	body := rec.Body.String()
+	if !strings.Contains(body, "first &lt;b&gt;") || strings.Contains(body, "second") {
+		t.Errorf("index doesn't contain only the first TODO: %s", body)
+	}
+	if rec := get(h, "GET", "/missing"); rec.Code != http.StatusNotFound {
+		t.Errorf("status = %d, want %d", rec.Code, http.StatusNotFound)
+	}
+}
+
+func TestServeFile(t *testing.T) {
+	h, root := newTestServer(t, map[string]string{
+		"main.go": "package main\n\n// TODO: here\n",
+	})
+	path := filepath.ToSlash(filepath.Join(root, "main.go"))
+	rec := get(h, "GET", "/file?line=3&path="+url.QueryEscape(path))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, body = %q", rec.Code, rec.Body)
+	}
+	if !strings.Contains(rec.Body.String(), "// TODO: here") {
+		t.Errorf("file doesn't contain the TODO line: %s", rec.Body)
+	}
+	// files which weren't scanned can't be viewed, even if they exist
+	outside := filepath.Join(t.TempDir(), "secret.txt")
+	if err := os.WriteFile(outside, []byte("secret\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	for _, p := range []string{
+		outside,
+		root + "/../" + filepath.Base(root) + "/main.go",
+		"main.go",
+		"",
+	} {
+		rec := get(h, "GET", "/file?path="+url.QueryEscape(filepath.ToSlash(p)))
+		if rec.Code != http.StatusNotFound {
+			t.Errorf("/file?path=%s status = %d, want %d", p, rec.Code, http.StatusNotFound)
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/icholy/todo"
+)
+
+// stampCommand adds created and author attributes to the TODOs which don't have them.
+func stampCommand(args []string) error {
+	fset := flag.NewFlagSet("stamp", flag.ExitOnError)
+	fset.Usage = func() {
+		fmt.Fprintln(fset.Output(), "usage: todo stamp [flags] <files>...")
+		fset.PrintDefaults()
+	}
+	author := fset.Bool("author", false, "also add the author attribute")
+	dryRun := fset.Bool("n", false, "print the stamped TODOs without writing the files")
+	keywordsFlag(fset)
+	fset.Parse(args)
+	if fset.NArg() == 0 {
+		fset.Usage()
+		return errors.New("expected files")
+	}
+	// uncommitted TODOs are stamped with the current date and git user
+	now := time.Now()
+	var user string
+	if *author {
+		out, err := exec.Command("git", "config", "user.name").Output()
+		if err != nil {
+			return errors.New("git user.name is not configured")
+		}
+		user = strings.TrimSpace(string(out))
+	}
+	for _, filename := range fset.Args() {
+		source, err := os.ReadFile(filename)
+		if err != nil {
+			return err
+		}
+		todos, err := todo.Parse(filename, source)
+		if err != nil {
+			return err
+		}
+		stamped, err := todo.Stamp(todos, now, user)
+		if err != nil {
+			return err
+		}
+		for _, t := range stamped {
+			fmt.Printf("%s %s\n", t.Location, t)
+		}
+		if len(stamped) == 0 || *dryRun {
+			continue
+		}
+		output, err := todo.Rewrite(source, stamped)
+		if err != nil {
+			return err
+		}
+		if err := writeFile(filename, output); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/icholy/todo"
+)
+
+// statsCommand prints a summary of the TODOs in each group.
+func statsCommand(args []string) error {
+	fset := flag.NewFlagSet("stats", flag.ExitOnError)
+	fset.Usage = func() {
+		fmt.Fprintln(fset.Output(), "usage: todo stats [flags] [files...]")
+		fset.PrintDefaults()
+	}
+	groupBy := groupByFlag(fset)
+	blame := fset.Bool("blame", false, "use the commit which introduced each TODO when it has no created attribute")
+	asJSON := fset.Bool("json", false, "print the statistics as JSON")
+	keywordsFlag(fset)
+	where := whereFlag(fset)
+	fset.Parse(args)
+	todos, err := parseFiles(fset.Args())
+	if err != nil {
+		return err
+	}
+	todos = where.Apply(todos)
+	if *blame {
+		if err := todo.Blame(todos); err != nil {
+			return err
+		}
+	}
+	now := time.Now()
+	groups := []todoStats{}
+	if *groupBy != "" {
+		for _, g := range todo.GroupBy(todos, *groupBy) {
+			groups = append(groups, newTodoStats(groupName(g), g.Todos, now))
+		}
+	}
+	total := newTodoStats("total", todos, now)
+	if *asJSON {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(map[string]any{
+			"groups": groups,
+			"total":  total,
+		})
+	}
+	tw := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "GROUP\tTODOS\tOVERDUE\tOLDEST\tNEWEST")
+	for _, s := range append(groups, total) {
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%s\t%s\n", s.Group, s.Todos, s.Overdue, formatDate(s.Oldest), formatDate(s.Newest))
+	}
+	return tw.Flush()
+}
+
+// todoStats summarizes a group of TODOs.
+type todoStats struct {
+	Group   string     `json:"group"`
+	Todos   int        `json:"todos"`
+	Overdue int        `json:"overdue"`
+	Oldest  *time.Time `json:"oldest,omitempty"`
+	Newest  *time.Time `json:"newest,omitempty"`
+}
+
+// newTodoStats counts the TODOs, and finds the oldest and newest created times.
+func newTodoStats(group string, todos []todo.Todo, now time.Time) todoStats {
+	s := todoStats{Group: group, Todos: len(todos)}
+	for _, t := range todos {
+		if t.Overdue(now) {
+			s.Overdue++
+		}
+		created, ok := t.Created()
+		if !ok {
+			continue
+		}
+		if s.Oldest == nil || created.Before(*s.Oldest) {
+			s.Oldest = &created
+		}
+		if s.Newest == nil || created.After(*s.Newest) {
+			s.Newest = &created
+		}
+	}
+	return s
+}
+
+// formatDate formats the date, or returns - if it's nil.
+func formatDate(t *time.Time) string {
+	if t == nil {
+		return "-"
+	}
+	return t.Format(time.DateOnly)
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+	"time"
+	"unicode/utf8"
+
+	"github.com/icholy/todo"
+)
+
+// templateFuncs are the helper functions available in output templates.
+var templateFuncs = template.FuncMap{
+	// attr returns the value of the attribute, or an empty string.
+	"attr": func(t todo.Todo, key string) string {
+		value, _ := t.Attribute(key)
+		return value
+	},
+	// rel returns the path relative to the working directory.
+	"rel": func(path string) string {
+		wd, err := os.Getwd()
+		if err != nil {
+			return path
+		}
+		abs, err := filepath.Abs(path)
+		if err != nil {
+			return path
+		}
+		rel, err := filepath.Rel(wd, abs)
+		if err != nil {
+			return path
+		}
+		return rel
+	},
+	// date formats a time, or a date or RFC 3339 string, using the layout.
+	// Values which aren't dates are returned unchanged.
+	"date": func(layout string, v any) string {
+		switch v := v.(type) {
+		case time.Time:
+			return v.Format(layout)
+		case *time.Time:
+			if v == nil {
+				return ""
+			}
+			return v.Format(layout)
+		case string:
+			for _, format := range []string{time.DateOnly, time.RFC3339} {
+				if t, err := time.Parse(format, v); err == nil {
+					return t.Format(layout)
+				}
+			}
+			return v
+		default:
+			return fmt.Sprint(v)
+		}
+	},
+	// pad pads the value with spaces to the width.
+	// A negative width pads on the left.
+	"pad": func(width int, v any) string {
+		s := fmt.Sprint(v)
+		n := utf8.RuneCountInString(s)
+		if width < 0 {
+			return strings.Repeat(" ", max(-width-n, 0)) + s
+		}
+		return s + strings.Repeat(" ", max(width-n, 0))
+	},
+	// json encodes the value as JSON.
+	"json": func(v any) (string, error) {
+		data, err := json.Marshal(v)
+		return string(data), err
+	},
+}
+
+// templateFlags adds the -template and -template-file flags.
+// The returned function parses the template after the flags are parsed,
+// and returns nil if neither flag is set.
+func templateFlags(fset *flag.FlagSet) func() (*template.Template, error) {
+	text := fset.String("template", "", "print each TODO using a Go text/template")
+	file := fset.String("template-file", "", "print each TODO using a Go text/template read from the `file`")
+	return func() (*template.Template, error) {
+		switch {
+		case *text != "" && *file != "":
+			return nil, errors.New("-template cannot be used with -template-file")
+		case *text != "":
+			return parseTemplate("template", *text)
+		case *file != "":
+			data, err := os.ReadFile(*file)
+			if err != nil {
+				return nil, err
+			}
+			return parseTemplate(filepath.Base(*file), string(data))
+		default:
+			return nil, nil
+		}
+	}
+}
+
+// parseTemplate parses a TODO template.
+// A newline is added if the template doesn't end with one.
+func parseTemplate(name, text string) (*template.Template, error) {
+	if !strings.HasSuffix(text, "\n") {
+		text += "\n"
+	}
+	return template.New(name).Funcs(templateFuncs).Parse(text)
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/icholy/todo"
+)
+
+func TestTemplateFuncs(t *testing.T) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	deadline := time.Date(2025, 3, 9, 12, 0, 0, 0, time.UTC)
+	data := map[string]any{
+		"Todo": todo.Todo{
+			Location:    todo.Location{File: filepath.Join(wd, "pkg", "main.go"), Line: 7},
+			Description: `say "héllo"`,
+			Attributes: []todo.Attribute{
+				{Key: "owner", Value: "alice"},
+				{Key: "created", Value: "2025-03-01"},
+			},
+		},
+		"Time":     deadline,
+		"TimePtr":  &deadline,
+		"NilTime":  (*time.Time)(nil),
+		"Relative": filepath.Join("pkg", "main.go"),
+	}
+	tests := []struct {
+		name string
+		text string
+		want string
+	}{
+		{name: "attr", text: `{{attr .Todo "owner"}}`, want: "alice"},
+		{name: "attr missing", text: `[{{attr .Todo "issue"}}]`, want: "[]"},
+		{name: "rel absolute", text: `{{rel .Todo.Location.File}}`, want: filepath.Join("pkg", "main.go")},
+		{name: "rel relative", text: `{{rel .Relative}}`, want: filepath.Join("pkg", "main.go")},
+		{name: "date time", text: `{{date "Jan 2" .Time}}`, want: "Mar 9"},
+		{name: "date pointer", text: `{{date "2006" .TimePtr}}`, want: "2025"},
+		{name: "date nil", text: `[{{date "2006" .NilTime}}]`, want: "[]"},
+		{name: "date string", text: `{{date "02/01/2006" (attr .Todo "created")}}`, want: "01/03/2025"},
+		{name: "date rfc3339", text: `{{date "15:04" "2025-03-09T08:30:00Z"}}`, want: "08:30"},
+		{name: "date invalid", text: `{{date "2006" "someday"}}`, want: "someday"},
+		{name: "date other", text: `{{date "2006" 42}}`, want: "42"},
+		{name: "pad right", text: `[{{pad 6 "héllo"}}]`, want: "[héllo ]"},
+		{name: "pad left", text: `[{{pad -4 .Todo.Location.Line}}]`, want: "[   7]"},
+		{name: "pad short", text: `[{{pad 2 "héllo"}}]`, want: "[héllo]"},
+		{name: "json string", text: `{{json .Todo.Description}}`, want: `"say \"héllo\""`},
+		{name: "json control", text: `{{json "a\tb\n"}}`, want: `"a\tb\n"`},
+		{name: "json html", text: `{{json "<a&b>"}}`, want: `"\u003ca\u0026b\u003e"`},
+		{name: "json number", text: `{{json .Todo.Location.Line}}`, want: "7"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpl, err := parseTemplate(tt.name, tt.text)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var b strings.Builder
+			if err := tmpl.Execute(&b, data); err != nil {
+				t.Fatalf("Execute() error = %v", err)
+			}
+			if got := strings.TrimSuffix(b.String(), "\n"); got != tt.want {
+				t.Errorf("Execute() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTemplateJSONError(t *testing.T) {
+	tmpl, err := parseTemplate("json", `{{json .}}`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := tmpl.Execute(&strings.Builder{}, func() {}); err == nil {
+		t.Error("Execute() expected an error for a value which can't be encoded")
+	}
+}
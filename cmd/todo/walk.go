@@ -0,0 +1,59 @@
+package main
+
+import (
+	"bytes"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/icholy/todo"
+)
+
+// walkFiles calls fn for each regular file under root.
+// Hidden files and directories, such as .git, are skipped.
+func walkFiles(root string, fn func(path string) error) error {
+	return filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if path != root && isHidden(d.Name()) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !d.Type().IsRegular() || isHidden(d.Name()) {
+			return nil
+		}
+		return fn(path)
+	})
+}
+
+// parseFile reads and parses a file found by walkFiles.
+// Files without a registered language are parsed as text,
+// and binary files don't have any TODOs.
+func parseFile(path string) ([]byte, []todo.Todo, error) {
+	source, err := os.ReadFile(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	if isBinary(source) {
+		return source, nil, nil
+	}
+	todos, err := todo.Parse(path, source)
+	if err != nil {
+		return nil, nil, err
+	}
+	return source, todos, nil
+}
+
+// isBinary reports whether the data looks like a binary file.
+func isBinary(data []byte) bool {
+	return bytes.IndexByte(data[:min(len(data), 8000)], 0) >= 0
+}
+
+// isHidden reports whether the file or directory name is hidden.
+func isHidden(name string) bool {
+	return strings.HasPrefix(name, ".") && name != "." && name != ".."
+}
@@ -0,0 +1,306 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/fs"
+	"log"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/icholy/todo"
+)
+
+// watchCommand prints the TODOs which change as files are edited.
+func watchCommand(args []string) error {
+	fset := flag.NewFlagSet("watch", flag.ExitOnError)
+	fset.Usage = func() {
+		fmt.Fprintln(fset.Output(), "usage: todo watch [flags] [dir]")
+		fset.PrintDefaults()
+	}
+	poll := fset.Duration("poll", 0, "poll for changes at this interval instead of using filesystem notifications")
+	debounce := fset.Duration("debounce", 200*time.Millisecond, "wait for changes to settle before rescanning")
+	asJSON := fset.Bool("json", false, "print the changes as JSON lines")
+	keywordsFlag(fset)
+	fset.Parse(args)
+	root := "."
+	if fset.NArg() > 0 {
+		root = fset.Arg(0)
+	}
+	w := &watcher{
+		root:  root,
+		files: map[string]watchedFile{},
+		print: printChange,
+	}
+	if *asJSON {
+		w.print = printChangeJSON
+	}
+	if err := w.scan(false); err != nil {
+		return err
+	}
+	ctx := context.Background()
+	if *poll == 0 {
+		err := w.notify(ctx, *debounce)
+		if err == nil {
+			return nil
+		}
+		log.Printf("filesystem notifications unavailable, polling: %v", err)
+		*poll = time.Second
+	}
+	w.poll(ctx, *poll)
+	return nil
+}
+
+// watchedFile is the last scanned state of a file.
+type watchedFile struct {
+	modTime time.Time
+	size    int64
+	todos   []todo.Todo
+}
+
+// watcher tracks the TODOs in a directory.
+// The files are guarded by the mutex, so the TODOs can be read while watching.
+type watcher struct {
+	root  string
+	print func(todo.Change) error
+
+	mu    sync.Mutex
+	files map[string]watchedFile
+}
+
+// todos returns the TODOs in all the files, sorted by file and line.
+func (w *watcher) todos() []todo.Todo {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	var todos []todo.Todo
+	for _, path := range slices.Sorted(maps.Keys(w.files)) {
+		todos = append(todos, w.files[path].todos...)
+	}
+	return todos
+}
+
+// hasFile reports whether the file is being tracked.
+func (w *watcher) hasFile(path string) bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	_, ok := w.files[path]
+	return ok
+}
+
+// scan walks the directory and rescans the files which changed.
+// Changes are only printed if report is true. Files which can't be
+// scanned are logged and skipped.
+func (w *watcher) scan(report bool) error {
+	seen := map[string]bool{}
+	err := walkFiles(w.root, func(path string) error {
+		seen[path] = true
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil
+		}
+		w.mu.Lock()
+		f, ok := w.files[path]
+		w.mu.Unlock()
+		if ok && f.modTime.Equal(info.ModTime()) && f.size == info.Size() {
+			return nil
+		}
+		if err := w.update(path, report); err != nil {
+			log.Printf("%s: %v", path, err)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	w.mu.Lock()
+	paths := slices.Collect(maps.Keys(w.files))
+	w.mu.Unlock()
+	for _, path := range paths {
+		if seen[path] {
+			continue
+		}
+		if err := w.update(path, report); err != nil {
+			log.Printf("%s: %v", path, err)
+		}
+	}
+	return nil
+}
+
+// update rescans a single file and prints the changes.
+// Files which no longer exist are removed. The file is parsed
+// before locking, so the TODOs can be read while it's parsed.
+func (w *watcher) update(path string, report bool) error {
+	var f watchedFile
+	info, err := os.Stat(path)
+	exists := err == nil && info.Mode().IsRegular()
+	if exists {
+		_, todos, err := parseFile(path)
+		if err != nil {
+			return err
+		}
+		f = watchedFile{modTime: info.ModTime(), size: info.Size(), todos: todos}
+	}
+	w.mu.Lock()
+	old := w.files[path].todos
+	if exists {
+		w.files[path] = f
+	} else {
+		delete(w.files, path)
+	}
+	w.mu.Unlock()
+	if report {
+		for _, c := range todo.Diff(old, f.todos) {
+			if err := w.print(c); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// poll rescans the directory at each interval until the context is done.
+func (w *watcher) poll(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := w.scan(true); err != nil {
+				log.Printf("scan: %v", err)
+			}
+		}
+	}
+}
+
+// notify rescans files when they're changed using filesystem notifications,
+// until the context is done.
+func (w *watcher) notify(ctx context.Context, debounce time.Duration) error {
+	fw, err := fsnotify.NewWatcher()
+	if err != nil {
+		return err
+	}
+	defer fw.Close()
+	err = filepath.WalkDir(w.root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || !d.IsDir() {
+			return err
+		}
+		if path != w.root && isHidden(d.Name()) {
+			return filepath.SkipDir
+		}
+		return fw.Add(path)
+	})
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	paths := make(chan string)
+	go func() {
+		defer close(paths)
+		send := func(path string) bool {
+			select {
+			case paths <- path:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		}
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case event, ok := <-fw.Events:
+				if !ok {
+					return
+				}
+				// use the same paths as the initial scan
+				name := filepath.Clean(event.Name)
+				if isHidden(filepath.Base(name)) {
+					continue
+				}
+				if event.Has(fsnotify.Create) {
+					if info, err := os.Stat(name); err == nil && info.IsDir() {
+						// new directories are watched, and their files scanned
+						fw.Add(name)
+						walkFiles(name, func(path string) error {
+							if !send(path) {
+								return filepath.SkipAll
+							}
+							return nil
+						})
+						continue
+					}
+				}
+				if !send(name) {
+					return
+				}
+			case err, ok := <-fw.Errors:
+				if !ok {
+					return
+				}
+				log.Printf("watch: %v", err)
+			}
+		}
+	}()
+	w.debounce(ctx, paths, debounce)
+	return nil
+}
+
+// debounce rescans the paths it receives. The paths are collected until
+// none have been received for the debounce duration, so a burst of writes
+// from an editor is rescanned once. It returns when the context is done
+// or the channel is closed.
+func (w *watcher) debounce(ctx context.Context, paths <-chan string, debounce time.Duration) {
+	pending := map[string]bool{}
+	timer := time.NewTimer(debounce)
+	timer.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case path, ok := <-paths:
+			if !ok {
+				return
+			}
+			pending[path] = true
+			timer.Reset(debounce)
+		case <-timer.C:
+			for path := range pending {
+				if err := w.update(path, true); err != nil {
+					log.Printf("%s: %v", path, err)
+				}
+			}
+			clear(pending)
+		}
+	}
+}
+
+// printChange prints a change in the same format as the diff command.
+func printChange(c todo.Change) error {
+	_, err := fmt.Println(c)
+	return err
+}
+
+// printChangeJSON prints a change as a JSON object on a single line.
+func printChangeJSON(c todo.Change) error {
+	t := c.Todo()
+	v := map[string]any{
+		"type": c.Type.String(),
+		"file": t.Location.File,
+		"line": t.Location.Line,
+		"todo": t.String(),
+	}
+	if c.Type == todo.Modified {
+		v["was"] = c.Old.String()
+	}
+	return json.NewEncoder(os.Stdout).Encode(v)
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/icholy/todo"
+)
+
+// newTestWatcher returns a watcher for a temporary directory
+// which sends the printed changes to the channel.
+func newTestWatcher(t *testing.T) (*watcher, chan string) {
+	t.Helper()
+	changes := make(chan string, 10)
+	w := &watcher{
+		root:  t.TempDir(),
+		files: map[string]watchedFile{},
+		print: func(c todo.Change) error {
+			changes <- c.String()
+			return nil
+		},
+	}
+	return w, changes
+}
+
+// writeTestFile writes a file in the watcher's directory and returns its path.
+func writeTestFile(t *testing.T, w *watcher, name, content string) string {
+	t.Helper()
+	path := filepath.Join(w.root, name)
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// receive returns the next change, or fails if there isn't one.
+func receive(t *testing.T, changes chan string) string {
+	t.Helper()
+	select {
+	case c := <-changes:
+		return c
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for a change")
+		return ""
+	}
+}
+
+func TestWatcherScan(t *testing.T) {
+	w, _ := newTestWatcher(t)
+	writeTestFile(t, w, "main.go", "package main\n\n// TODO: code\n")
+	writeTestFile(t, w, "notes.txt", "TODO: text\n")
+	writeTestFile(t, w, "Makefile", "# TODO: make\n")
+	writeTestFile(t, w, "image.bin", "TODO: binary\x00\n")
+	writeTestFile(t, w, ".hidden", "TODO: hidden\n")
+	// unreadable files are skipped without stopping the scan
+	unreadable := writeTestFile(t, w, "unreadable.txt", "TODO: unreadable\n")
+	if err := os.Chmod(unreadable, 0); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.scan(false); err != nil {
+		t.Fatalf("scan() error = %v", err)
+	}
+	var got []string
+	for _, t := range w.todos() {
+		got = append(got, t.String())
+	}
+	want := []string{"TODO: make", "TODO: code", "TODO: text"}
+	if os.Getuid() == 0 {
+		// root can read the file anyway
+		want = append(want, "TODO: unreadable")
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("todos() = %q, want %q", got, want)
+	}
+}
+
+func TestWatcherDebounce(t *testing.T) {
+	w, changes := newTestWatcher(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	paths := make(chan string)
+	done := make(chan struct{})
+	go func() {
+		w.debounce(ctx, paths, 100*time.Millisecond)
+		close(done)
+	}()
+	// a burst of writes is only rescanned after the last one
+	path := writeTestFile(t, w, "main.go", "package main\n\n// TODO: first\n")
+	paths <- path
+	writeTestFile(t, w, "main.go", "package main\n\n// TODO: second\n")
+	paths <- path
+	if got, want := receive(t, changes), "+ "+path+":3 TODO: second"; got != want {
+		t.Errorf("change = %q, want %q", got, want)
+	}
+	select {
+	case c := <-changes:
+		t.Errorf("unexpected change %q", c)
+	case <-time.After(200 * time.Millisecond):
+	}
+	close(paths)
+	<-done
+}
+
+func TestWatcherPoll(t *testing.T) {
+	w, changes := newTestWatcher(t)
+	if err := w.scan(false); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		w.poll(ctx, 10*time.Millisecond)
+		close(done)
+	}()
+	path := writeTestFile(t, w, "notes.txt", "TODO: added\n")
+	if got, want := receive(t, changes), "+ "+path+":1 TODO: added"; got != want {
+		t.Errorf("change = %q, want %q", got, want)
+	}
+	if err := os.Remove(path); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := receive(t, changes), "- "+path+":1 TODO: added"; got != want {
+		t.Errorf("change = %q, want %q", got, want)
+	}
+	cancel()
+	<-done
+}
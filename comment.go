@@ -0,0 +1,95 @@
+package todo
+
+import (
+	"bytes"
+	"strings"
+)
+
+// CommentKind is the kind of comment a TODO was found in.
+type CommentKind int
+
+const (
+	// PlainText is used for TODOs in files without a registered language.
+	PlainText CommentKind = iota
+	// LineComment is a comment which ends at the end of the line.
+	LineComment
+	// BlockComment is a delimited comment such as /* ... */.
+	BlockComment
+	// DocComment is a documentation comment such as /** ... */ or ///.
+	DocComment
+	// Docstring is a string literal used as documentation.
+	Docstring
+)
+
+var commentKindNames = []string{"text", "line", "block", "doc", "docstring"}
+
+// String returns the name of the comment kind.
+func (k CommentKind) String() string {
+	if k < 0 || int(k) >= len(commentKindNames) {
+		return "unknown"
+	}
+	return commentKindNames[k]
+}
+
+// captureKind returns the comment kind for a capture name.
+// The kind is only known for the @comment.line, @comment.block,
+// @comment.doc and @comment.docstring captures.
+func captureKind(name string) (CommentKind, bool) {
+	switch name {
+	case "comment.line":
+		return LineComment, true
+	case "comment.block":
+		return BlockComment, true
+	case "comment.doc":
+		return DocComment, true
+	case "comment.docstring":
+		return Docstring, true
+	default:
+		return 0, false
+	}
+}
+
+// isCommentCapture reports whether the capture name is used for comments.
+func isCommentCapture(name string) bool {
+	return name == "comment" || strings.HasPrefix(name, "comment.")
+}
+
+var (
+	docPrefixes   = []string{"///", "//!", "/**", "/*!", "(**"}
+	blockPrefixes = []string{"/*", "(*", "<!--", "{-", "=begin"}
+	closers       = []string{"*/", "*)", "-->", "-}", "=end"}
+	quotes        = []string{`"""`, `'''`, `"`, `'`}
+)
+
+// inferKind guesses the comment kind from the comment text.
+func inferKind(comment []byte) CommentKind {
+	comment = bytes.TrimSpace(comment)
+	for _, prefix := range docPrefixes {
+		if bytes.HasPrefix(comment, []byte(prefix)) && !bytes.Equal(comment, []byte("/**/")) {
+			return DocComment
+		}
+	}
+	for _, prefix := range blockPrefixes {
+		if bytes.HasPrefix(comment, []byte(prefix)) {
+			return BlockComment
+		}
+	}
+	if bytes.ContainsRune(comment, '\n') {
+		return BlockComment
+	}
+	return LineComment
+}
+
+// trimCloser removes a trailing comment or string delimiter from the description.
+func trimCloser(description string, kind CommentKind) string {
+	closers := closers
+	if kind == Docstring {
+		closers = quotes
+	}
+	for _, closer := range closers {
+		if s, ok := strings.CutSuffix(description, closer); ok {
+			return strings.TrimSpace(s)
+		}
+	}
+	return description
+}
@@ -0,0 +1,119 @@
+package todo
+
+import (
+	"bytes"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// modelineLines is the number of lines at the start and end of
+// a file which are searched for vim and emacs modelines.
+const modelineLines = 5
+
+// Detect returns the language for the given file name and source.
+// The source may be nil, in which case only the file name is used.
+// Detection methods are tried in the following order:
+//
+//  1. overrides registered with Registry.Override
+//  2. vim or emacs modeline
+//  3. exact file name
+//  4. file name glob pattern
+//  5. file extension
+//  6. shebang interpreter
+func (r *Registry) Detect(file string, source []byte) (*LanguageOptions, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for i := len(r.overrides) - 1; i >= 0; i-- {
+		if matchPattern(r.overrides[i].pattern, file) {
+			if l, ok := r.names[strings.ToLower(r.overrides[i].name)]; ok {
+				return l, true
+			}
+		}
+	}
+	if name, ok := modeline(source); ok {
+		if l, ok := r.names[name]; ok {
+			return l, true
+		}
+	}
+	if l, ok := r.filenames[filepath.Base(file)]; ok {
+		return l, true
+	}
+	for _, p := range r.patterns {
+		if matchPattern(p.pattern, file) {
+			return p.lang, true
+		}
+	}
+	if l, ok := r.extensions[filepath.Ext(file)]; ok {
+		return l, true
+	}
+	if name, ok := shebang(source); ok {
+		if l, ok := r.interpreters[name]; ok {
+			return l, true
+		}
+		if l, ok := r.interpreters[strings.TrimRight(name, "0123456789.")]; ok {
+			return l, true
+		}
+	}
+	return nil, false
+}
+
+// shebang returns the interpreter name from the first line of source.
+// The env program is skipped along with its flags and variable assignments.
+func shebang(source []byte) (string, bool) {
+	if !bytes.HasPrefix(source, []byte("#!")) {
+		return "", false
+	}
+	line, _, _ := bytes.Cut(source[2:], []byte("\n"))
+	fields := strings.Fields(string(line))
+	if len(fields) == 0 {
+		return "", false
+	}
+	name := path.Base(fields[0])
+	if name == "env" {
+		name = ""
+		for _, f := range fields[1:] {
+			if strings.HasPrefix(f, "-") || strings.Contains(f, "=") {
+				continue
+			}
+			name = path.Base(f)
+			break
+		}
+	}
+	return name, name != ""
+}
+
+var (
+	emacsModeline = regexp.MustCompile(`-\*-(.*?)-\*-`)
+	emacsMode     = regexp.MustCompile(`(?i)(?:^|;)\s*mode\s*:\s*([^;\s]+)`)
+	vimModeline   = regexp.MustCompile(`(?:^|\s)(?:vi|vim|ex)(?:[<=>]?\d+)?:(?:.*?[\s:])?(?:ft|filetype|syn|syntax)=([\w+#.-]+)`)
+)
+
+// modeline returns the lower-cased language name from a vim or emacs
+// modeline in the first or last few lines of source.
+func modeline(source []byte) (string, bool) {
+	if len(source) == 0 {
+		return "", false
+	}
+	// a trailing newline doesn't start another line
+	lines := bytes.Split(bytes.TrimSuffix(source, []byte("\n")), []byte("\n"))
+	if len(lines) > modelineLines*2 {
+		lines = append(lines[:modelineLines], lines[len(lines)-modelineLines:]...)
+	}
+	for _, line := range lines {
+		if m := emacsModeline.FindSubmatch(line); m != nil {
+			vars := string(m[1])
+			if !strings.Contains(vars, ":") {
+				return strings.ToLower(strings.TrimSpace(vars)), true
+			}
+			if m := emacsMode.FindStringSubmatch(vars); m != nil {
+				return strings.ToLower(m[1]), true
+			}
+		}
+		if m := vimModeline.FindSubmatch(line); m != nil {
+			return strings.ToLower(string(m[1])), true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,155 @@
+package todo
+
+import "testing"
+
+func TestDetectLanguage(t *testing.T) {
+	tests := []struct {
+		name   string
+		file   string
+		source string
+		want   string
+		ok     bool
+	}{
+		{
+			name: "extension",
+			file: "main.go",
+			want: "Golang",
+			ok:   true,
+		},
+		{
+			name: "filename",
+			file: "project/Rakefile",
+			want: "Ruby",
+			ok:   true,
+		},
+		{
+			name: "pattern",
+			file: "todo.gemspec",
+			want: "Ruby",
+			ok:   true,
+		},
+		{
+			name:   "shebang",
+			file:   "bin/script",
+			source: "#!/usr/bin/python3\nprint('hello')\n",
+			want:   "Python",
+			ok:     true,
+		},
+		{
+			name:   "shebang env",
+			file:   "bin/script",
+			source: "#!/usr/bin/env -S NODE_ENV=dev node --harmony\n",
+			want:   "JavaScript",
+			ok:     true,
+		},
+		{
+			name:   "shebang versioned",
+			file:   "bin/script",
+			source: "#!/usr/bin/env python3.12\n",
+			want:   "Python",
+			ok:     true,
+		},
+		{
+			name:   "extension beats shebang",
+			file:   "script.rb",
+			source: "#!/bin/sh\n",
+			want:   "Ruby",
+			ok:     true,
+		},
+		{
+			name:   "vim modeline",
+			file:   "script.txt",
+			source: "line\n# vim: set ft=ruby ts=2:\n",
+			want:   "Ruby",
+			ok:     true,
+		},
+		{
+			name:   "vim modeline short",
+			file:   "script",
+			source: "// vi:ft=javascript\n",
+			want:   "JavaScript",
+			ok:     true,
+		},
+		{
+			name:   "emacs modeline",
+			file:   "script.txt",
+			source: "# -*- mode: python; coding: utf-8 -*-\n",
+			want:   "Python",
+			ok:     true,
+		},
+		{
+			name:   "emacs modeline short",
+			file:   "script.txt",
+			source: "/* -*- c++ -*- */\n",
+			want:   "C++",
+			ok:     true,
+		},
+		{
+			name:   "modeline beats extension",
+			file:   "config.h",
+			source: "// vim: ft=cpp\n",
+			want:   "C++",
+			ok:     true,
+		},
+		{
+			name:   "modeline at end",
+			file:   "script",
+			source: "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n# vim: ft=sh\n",
+			want:   "Bash",
+			ok:     true,
+		},
+		{
+			name:   "modeline in middle",
+			file:   "script",
+			source: "1\n2\n3\n4\n5\n# vim: ft=sh\n7\n8\n9\n10\n11\n12\n",
+			ok:     false,
+		},
+		{
+			name:   "modeline fifth from end",
+			file:   "script",
+			source: "1\n2\n3\n4\n5\n6\n7\n# vim: ft=sh\n9\n10\n11\n12\n",
+			want:   "Bash",
+			ok:     true,
+		},
+		{
+			name: "makefile",
+			file: "src/Makefile",
+			want: "Makefile",
+			ok:   true,
+		},
+		{
+			name: "gnu makefile",
+			file: "GNUmakefile",
+			want: "Makefile",
+			ok:   true,
+		},
+		{
+			name: "dockerfile",
+			file: "Dockerfile",
+			want: "Dockerfile",
+			ok:   true,
+		},
+		{
+			name: "dockerfile pattern",
+			file: "build/release.dockerfile",
+			want: "Dockerfile",
+			ok:   true,
+		},
+		{
+			name: "unknown",
+			file: "LICENSE",
+			ok:   false,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := DetectLanguage(tt.file, []byte(tt.source))
+			if ok != tt.ok {
+				t.Fatalf("DetectLanguage(%q) = got ok=%v, want ok=%v", tt.file, ok, tt.ok)
+			}
+			if ok && got.Name != tt.want {
+				t.Errorf("DetectLanguage(%q) = %q, want %q", tt.file, got.Name, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,205 @@
+package todo
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+)
+
+// ChangeType is the type of a Change.
+type ChangeType int
+
+const (
+	Added ChangeType = iota + 1
+	Removed
+	Modified
+)
+
+// String returns the name of the change type.
+func (c ChangeType) String() string {
+	switch c {
+	case Added:
+		return "added"
+	case Removed:
+		return "removed"
+	case Modified:
+		return "modified"
+	default:
+		return "unknown"
+	}
+}
+
+// rank orders removals before modifications and additions on the same line.
+func (c ChangeType) rank() int {
+	switch c {
+	case Removed:
+		return 0
+	case Modified:
+		return 1
+	default:
+		return 2
+	}
+}
+
+// Change is a difference between two sets of TODOs.
+type Change struct {
+	Type ChangeType
+	// Old is nil for Added changes.
+	Old *Todo
+	// New is nil for Removed changes.
+	New *Todo
+}
+
+// Todo returns the new TODO, or the old one if it was removed.
+func (c Change) Todo() Todo {
+	if c.New != nil {
+		return *c.New
+	}
+	return *c.Old
+}
+
+// String returns a string representation.
+func (c Change) String() string {
+	switch c.Type {
+	case Added:
+		return fmt.Sprintf("+ %s %s", c.New.Location, c.New)
+	case Removed:
+		return fmt.Sprintf("- %s %s", c.Old.Location, c.Old)
+	default:
+		return fmt.Sprintf("~ %s %s (was %s)", c.New.Location, c.New, c.Old)
+	}
+}
+
+// DiffSummary counts the changes of each type.
+type DiffSummary struct {
+	Added    int
+	Removed  int
+	Modified int
+}
+
+// Summarize counts the changes of each type.
+func Summarize(changes []Change) DiffSummary {
+	var s DiffSummary
+	for _, c := range changes {
+		switch c.Type {
+		case Added:
+			s.Added++
+		case Removed:
+			s.Removed++
+		case Modified:
+			s.Modified++
+		}
+	}
+	return s
+}
+
+// String returns a string representation.
+func (s DiffSummary) String() string {
+	return fmt.Sprintf("%d added, %d removed, %d modified", s.Added, s.Removed, s.Modified)
+}
+
+// Diff returns the changes between the base and head TODOs.
+// TODOs are matched by file and content rather than by line number,
+// so TODOs which only moved are not reported. A TODO in the same file
+// with the same description but different attributes is reported
+// as Modified. The changes are sorted by file and line.
+func Diff(base, head []Todo) []Change {
+	var changes []Change
+	matches := matchTodos(base, head)
+	matched := make([]bool, len(base))
+	for i, m := range matches {
+		switch {
+		case m.base < 0:
+			changes = append(changes, Change{Type: Added, New: &head[i]})
+		case m.modified:
+			changes = append(changes, Change{Type: Modified, Old: &base[m.base], New: &head[i]})
+		}
+		if m.base >= 0 {
+			matched[m.base] = true
+		}
+	}
+	for j := range base {
+		if !matched[j] {
+			changes = append(changes, Change{Type: Removed, Old: &base[j]})
+		}
+	}
+	slices.SortStableFunc(changes, func(a, b Change) int {
+		ta, tb := a.Todo(), b.Todo()
+		return cmp.Or(
+			cmp.Compare(ta.Location.File, tb.Location.File),
+			cmp.Compare(ta.Location.Line, tb.Location.Line),
+			cmp.Compare(a.Type.rank(), b.Type.rank()),
+		)
+	})
+	return changes
+}
+
+// todoMatch is the base TODO matched to a head TODO.
+type todoMatch struct {
+	// base is the index of the matching base TODO, or -1 if there is none.
+	base int
+	// modified is true if the match is by description only.
+	modified bool
+}
+
+// matchTodos matches each of the head TODOs to a base TODO.
+// Exact matches in the same file are preferred, followed by
+// TODOs in the same file with the same description.
+func matchTodos(base, head []Todo) []todoMatch {
+	matches := make([]todoMatch, len(head))
+	matched := make([]bool, len(base))
+	exact := indexBy(base, func(t Todo) string {
+		return t.Location.File + "\x00" + t.String()
+	})
+	for i, t := range head {
+		matches[i].base = -1
+		if j, ok := exact.take(t.Location.File + "\x00" + t.String()); ok {
+			matches[i].base = j
+			matched[j] = true
+		}
+	}
+	byDesc := indexBy(base, func(t Todo) string {
+		return t.Location.File + "\x00" + t.Description
+	})
+	for i, t := range head {
+		if matches[i].base >= 0 {
+			continue
+		}
+		key := t.Location.File + "\x00" + t.Description
+		for {
+			j, ok := byDesc.take(key)
+			if !ok {
+				break
+			}
+			if !matched[j] {
+				matched[j] = true
+				matches[i] = todoMatch{base: j, modified: true}
+				break
+			}
+		}
+	}
+	return matches
+}
+
+// todoIndex maps keys to queues of TODO indexes.
+type todoIndex map[string][]int
+
+// indexBy returns an index of the TODOs using the key function.
+func indexBy(todos []Todo, key func(Todo) string) todoIndex {
+	index := todoIndex{}
+	for i, t := range todos {
+		k := key(t)
+		index[k] = append(index[k], i)
+	}
+	return index
+}
+
+// take removes and returns the first index for the key.
+func (x todoIndex) take(key string) (int, bool) {
+	indexes := x[key]
+	if len(indexes) == 0 {
+		return 0, false
+	}
+	x[key] = indexes[1:]
+	return indexes[0], true
+}
@@ -0,0 +1,94 @@
+package todo
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	todo := func(file string, line int, text string) Todo {
+		t.Helper()
+		parsed, ok := parseLine([]byte(text), defaultKeywords)
+		if !ok {
+			t.Fatalf("invalid todo: %q", text)
+		}
+		parsed.Location = Location{File: file, Line: line}
+		return parsed
+	}
+	tests := []struct {
+		name string
+		base []Todo
+		head []Todo
+		want []string
+	}{
+		{
+			name: "empty",
+		},
+		{
+			name: "moved",
+			base: []Todo{todo("a.go", 1, "TODO: one")},
+			head: []Todo{todo("a.go", 10, "TODO: one")},
+		},
+		{
+			name: "added and removed",
+			base: []Todo{todo("a.go", 1, "TODO: one")},
+			head: []Todo{todo("a.go", 1, "TODO: two")},
+			want: []string{
+				"- a.go:1 TODO: one",
+				"+ a.go:1 TODO: two",
+			},
+		},
+		{
+			name: "modified attributes",
+			base: []Todo{todo("a.go", 1, "TODO(owner=alice): one")},
+			head: []Todo{todo("a.go", 2, "TODO(owner=bob): one")},
+			want: []string{
+				"~ a.go:2 TODO(owner=bob): one (was TODO(owner=alice): one)",
+			},
+		},
+		{
+			name: "different files",
+			base: []Todo{todo("a.go", 1, "TODO: one")},
+			head: []Todo{todo("b.go", 1, "TODO: one")},
+			want: []string{
+				"- a.go:1 TODO: one",
+				"+ b.go:1 TODO: one",
+			},
+		},
+		{
+			name: "duplicates",
+			base: []Todo{todo("a.go", 1, "TODO: one")},
+			head: []Todo{todo("a.go", 1, "TODO: one"), todo("a.go", 5, "TODO: one")},
+			want: []string{
+				"+ a.go:5 TODO: one",
+			},
+		},
+		{
+			name: "exact match preferred over modified",
+			base: []Todo{todo("a.go", 1, "TODO(x): one"), todo("a.go", 2, "TODO: one")},
+			head: []Todo{todo("a.go", 1, "TODO: one")},
+			want: []string{
+				"- a.go:1 TODO(x): one",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, c := range Diff(tt.base, tt.head) {
+				got = append(got, c.String())
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Diff() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSummarize(t *testing.T) {
+	changes := []Change{{Type: Added}, {Type: Added}, {Type: Removed}}
+	want := "2 added, 1 removed, 0 modified"
+	if got := Summarize(changes).String(); got != want {
+		t.Errorf("Summarize() = %q, want %q", got, want)
+	}
+}
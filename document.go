@@ -0,0 +1,223 @@
+package todo
+
+import (
+	"bytes"
+	"cmp"
+	"fmt"
+	"slices"
+
+	treesitter "github.com/tree-sitter/go-tree-sitter"
+)
+
+// TextEdit replaces the bytes from Start to End with Text.
+type TextEdit struct {
+	Start int
+	End   int
+	Text  string
+}
+
+// Document is a source file which is reparsed incrementally as it's edited.
+// The syntax tree is kept between edits, and only the comments in the changed
+// parts of the tree are searched for TODOs. A Document must be closed after use,
+// and is not safe for concurrent use.
+type Document struct {
+	registry *Registry
+	file     string
+	lang     *LanguageOptions
+	source   []byte
+	parser   *treesitter.Parser
+	tree     *treesitter.Tree
+	todos    []Todo
+}
+
+// NewDocument parses the source and returns a Document.
+// If lang is nil, the language is detected from the file name and source,
+// and files without a language are parsed as plain text.
+func (r *Registry) NewDocument(file string, source []byte, lang *LanguageOptions) (*Document, error) {
+	if lang == nil {
+		lang, _ = r.Detect(file, source)
+	}
+	d := &Document{
+		registry: r,
+		file:     file,
+		lang:     lang,
+		source:   slices.Clone(source),
+	}
+	if lang != nil {
+		d.parser = treesitter.NewParser()
+		if err := d.parser.SetLanguage(lang.Language); err != nil {
+			d.parser.Close()
+			return nil, err
+		}
+	}
+	if err := d.parse(); err != nil {
+		d.Close()
+		return nil, err
+	}
+	return d, nil
+}
+
+// NewDocument parses the source and returns a Document using the default registry.
+func NewDocument(file string, source []byte, lang *LanguageOptions) (*Document, error) {
+	return DefaultRegistry.NewDocument(file, source, lang)
+}
+
+// Close releases the syntax tree.
+func (d *Document) Close() {
+	if d.tree != nil {
+		d.tree.Close()
+	}
+	if d.parser != nil {
+		d.parser.Close()
+	}
+}
+
+// Source returns the current source.
+func (d *Document) Source() []byte {
+	return d.source
+}
+
+// Todos returns the TODOs in the current source, sorted by position.
+func (d *Document) Todos() []Todo {
+	return d.todos
+}
+
+// parse parses the whole source.
+func (d *Document) parse() error {
+	if d.lang == nil {
+		d.todos = d.registry.ParseText(d.file, d.source)
+		return nil
+	}
+	if d.tree != nil {
+		d.tree.Close()
+	}
+	d.tree = d.parser.Parse(d.source, nil)
+	todos, err := d.registry.treeTodos(d.file, d.source, d.lang, d.tree, nil, 0)
+	if err != nil {
+		return err
+	}
+	d.setTodos(todos)
+	return nil
+}
+
+// setTodos sorts the TODOs by position.
+func (d *Document) setTodos(todos []Todo) {
+	slices.SortStableFunc(todos, func(a, b Todo) int {
+		return cmp.Compare(a.Span.Start, b.Span.Start)
+	})
+	d.todos = todos
+}
+
+// Edit applies the edits in order and returns the changes to the TODOs.
+// The offsets of each edit are relative to the source after the previous edits.
+// The changes are computed with Diff, so TODOs which only moved aren't included.
+func (d *Document) Edit(edits ...TextEdit) ([]Change, error) {
+	old := d.todos
+	for _, e := range edits {
+		if err := d.edit(e); err != nil {
+			return nil, err
+		}
+	}
+	return Diff(old, d.todos), nil
+}
+
+// Update replaces the source and returns the changes to the TODOs.
+// The difference between the sources is applied as a single edit,
+// so the document is reparsed incrementally.
+func (d *Document) Update(source []byte) ([]Change, error) {
+	prefix := 0
+	for prefix < len(d.source) && prefix < len(source) && d.source[prefix] == source[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(d.source)-prefix && suffix < len(source)-prefix &&
+		d.source[len(d.source)-suffix-1] == source[len(source)-suffix-1] {
+		suffix++
+	}
+	return d.Edit(TextEdit{
+		Start: prefix,
+		End:   len(d.source) - suffix,
+		Text:  string(source[prefix : len(source)-suffix]),
+	})
+}
+
+// edit applies a single edit and updates the TODOs.
+func (d *Document) edit(e TextEdit) error {
+	if e.Start < 0 || e.End < e.Start || e.End > len(d.source) {
+		return fmt.Errorf("invalid edit range %d-%d", e.Start, e.End)
+	}
+	var source []byte
+	source = append(source, d.source[:e.Start]...)
+	source = append(source, e.Text...)
+	source = append(source, d.source[e.End:]...)
+	newEnd := e.Start + len(e.Text)
+	// injected languages depend on their host, so they're reparsed in full
+	if d.lang == nil || d.lang.injections != nil {
+		d.source = source
+		return d.parse()
+	}
+	d.tree.Edit(&treesitter.InputEdit{
+		StartByte:      uint(e.Start),
+		OldEndByte:     uint(e.End),
+		NewEndByte:     uint(newEnd),
+		StartPosition:  pointAt(d.source, e.Start),
+		OldEndPosition: pointAt(d.source, e.End),
+		NewEndPosition: pointAt(source, newEnd),
+	})
+	tree := d.parser.Parse(source, d.tree)
+	changed := d.tree.ChangedRanges(tree)
+	d.tree.Close()
+	d.tree = tree
+	// the TODOs after the edit are moved by the size of the edit
+	delta := newEnd - e.End
+	lines := bytes.Count([]byte(e.Text), []byte("\n")) - bytes.Count(d.source[e.Start:e.End], []byte("\n"))
+	var todos []Todo
+	for _, t := range d.todos {
+		switch {
+		case t.Span.End <= e.Start:
+		case t.Span.Start >= e.End:
+			t.shift(delta)
+			t.Location.Line += lines
+		default:
+			continue
+		}
+		todos = append(todos, t)
+	}
+	d.source = source
+	for i := range todos {
+		todos[i].Location.Column = columnAt(source, todos[i].Span.Start)
+	}
+	// the comments in the edited and changed ranges are parsed again
+	region := Span{Start: e.Start, End: newEnd}
+	for _, r := range changed {
+		region.Start = min(region.Start, int(r.StartByte))
+		region.End = max(region.End, int(r.EndByte))
+	}
+	region.Start = bytes.LastIndexByte(source[:region.Start], '\n') + 1
+	if i := bytes.IndexByte(source[region.End:], '\n'); i >= 0 {
+		region.End += i + 1
+	} else {
+		region.End = len(source)
+	}
+	comments := d.registry.captureComments(d.lang, d.tree, source, &region)
+	for _, c := range comments {
+		region.Start = min(region.Start, int(c.start))
+		region.End = max(region.End, int(c.end))
+	}
+	todos = slices.DeleteFunc(todos, func(t Todo) bool {
+		return t.Span.Start < region.End && t.Span.End > region.Start
+	})
+	keywords := d.registry.Keywords()
+	for _, c := range comments {
+		todos = append(todos, c.todos(d.file, source, keywords)...)
+	}
+	d.setTodos(todos)
+	return nil
+}
+
+// pointAt returns the row and byte column of the offset.
+func pointAt(source []byte, offset int) treesitter.Point {
+	row := bytes.Count(source[:offset], []byte("\n"))
+	column := offset - (bytes.LastIndexByte(source[:offset], '\n') + 1)
+	return treesitter.Point{Row: uint(row), Column: uint(column)}
+}
@@ -0,0 +1,109 @@
+package todo
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDocument(t *testing.T) {
+	source := "package main\n\n// TODO: one\nfunc main() {\n\t/* TODO: two\n\t   TODO: three */\n\tx := 1 // TODO: four\n}\n"
+	doc, err := NewDocument("main.go", []byte(source), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer doc.Close()
+	tests := []struct {
+		name    string
+		old     string
+		new     string
+		changes []string
+	}{
+		{
+			name:    "add line",
+			old:     "package main\n",
+			new:     "package main\n\n// TODO: zero\n",
+			changes: []string{"+ main.go:3 TODO: zero"},
+		},
+		{
+			name:    "edit description",
+			old:     "TODO: one",
+			new:     "TODO(owner=bob): one",
+			changes: []string{"~ main.go:5 TODO(owner=bob): one (was TODO: one)"},
+		},
+		{
+			name: "edit code on the same line",
+			old:  "x := 1",
+			new:  "xyz := 12345",
+		},
+		{
+			name:    "edit block comment",
+			old:     "/* TODO: two\n",
+			new:     "/* TODO: two\n\n",
+			changes: nil,
+		},
+		{
+			name:    "remove line",
+			old:     "\t   TODO: three */\n",
+			new:     "\t   */\n",
+			changes: []string{"- main.go:9 TODO: three"},
+		},
+		{
+			name:    "doc comment",
+			old:     "// TODO(owner=bob): one\n",
+			new:     "// TODO(owner=bob): one\nfunc Doc() {}\n",
+			changes: nil,
+		},
+		{
+			name:    "insert comment before code",
+			old:     "\txyz := 12345",
+			new:     "\t/* TODO: five */ xyz := 12345",
+			changes: []string{"+ main.go:11 TODO: five"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start := strings.Index(source, tt.old)
+			if start < 0 {
+				t.Fatalf("%q not found in source", tt.old)
+			}
+			changes, err := doc.Edit(TextEdit{Start: start, End: start + len(tt.old), Text: tt.new})
+			if err != nil {
+				t.Fatal(err)
+			}
+			source = source[:start] + tt.new + source[start+len(tt.old):]
+			var got []string
+			for _, c := range changes {
+				got = append(got, c.String())
+			}
+			if !reflect.DeepEqual(got, tt.changes) {
+				t.Errorf("Edit() = %q, want %q", got, tt.changes)
+			}
+			want, err := ParseCode("main.go", []byte(source), nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(doc.Todos(), want) {
+				t.Errorf("Todos() = %#v, want %#v", doc.Todos(), want)
+			}
+		})
+	}
+}
+
+func TestDocumentUpdate(t *testing.T) {
+	doc, err := NewDocument("notes.txt", []byte("TODO: one\n"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer doc.Close()
+	changes, err := doc.Update([]byte("TODO: one\nTODO: two\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changes) != 1 || changes[0].String() != "+ notes.txt:2 TODO: two" {
+		t.Errorf("Update() = %v, want the added TODO", changes)
+	}
+	if got := string(doc.Source()); got != "TODO: one\nTODO: two\n" {
+		t.Errorf("Source() = %q", got)
+	}
+}
@@ -0,0 +1,423 @@
+package todo
+
+import (
+	"cmp"
+	"fmt"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Filter is a compiled filter expression which matches TODOs.
+// The zero value matches every TODO.
+//
+// An expression compares fields with values:
+//
+//	owner == "alice" && deadline < 2026-01-01 && path ~ "pkg/**"
+//
+// The fields are path, line, keyword, description, kind and deadline, which is
+// the value of the deadline or due attribute used by Todo.Deadline. Any other name
+// is an attribute, and a name on its own matches TODOs which have it.
+// The operators are ==, !=, <, <=, >, >=, ~ (glob) and =~ (regexp), and
+// comparisons are combined with &&, ||, ! and parentheses.
+// Values are bare words or quoted strings, where single quoted strings
+// have no escapes. Values which are both dates or both numbers are compared
+// as such, and ordering a date with a value which isn't a date is false.
+// Comparisons with a missing attribute are false, except for !=.
+type Filter struct {
+	expr filterExpr
+	src  string
+}
+
+// ParseFilter compiles a filter expression.
+func ParseFilter(s string) (*Filter, error) {
+	p := &filterParser{src: s}
+	p.next()
+	expr, err := p.or()
+	if err != nil {
+		return nil, err
+	}
+	if p.err != nil || p.tok.kind != tokEOF {
+		return nil, p.errorf("unexpected %s", p.tok)
+	}
+	return &Filter{expr: expr, src: s}, nil
+}
+
+// String returns the source of the expression.
+func (f *Filter) String() string {
+	return f.src
+}
+
+// Match reports whether the TODO matches the expression.
+func (f *Filter) Match(t Todo) bool {
+	if f == nil || f.expr == nil {
+		return true
+	}
+	return f.expr.match(t)
+}
+
+// Apply returns the TODOs which match the expression.
+func (f *Filter) Apply(todos []Todo) []Todo {
+	var matched []Todo
+	for _, t := range todos {
+		if f.Match(t) {
+			matched = append(matched, t)
+		}
+	}
+	return matched
+}
+
+// filterExpr is a node in a filter expression.
+type filterExpr interface {
+	match(t Todo) bool
+}
+
+type (
+	orExpr  struct{ x, y filterExpr }
+	andExpr struct{ x, y filterExpr }
+	notExpr struct{ x filterExpr }
+	// hasExpr matches TODOs which have a non-empty field or the attribute.
+	hasExpr struct{ name string }
+	// cmpExpr compares a field or attribute with a value.
+	cmpExpr struct {
+		name  string
+		op    string
+		value string
+		re    *regexp.Regexp
+	}
+)
+
+func (e orExpr) match(t Todo) bool  { return e.x.match(t) || e.y.match(t) }
+func (e andExpr) match(t Todo) bool { return e.x.match(t) && e.y.match(t) }
+func (e notExpr) match(t Todo) bool { return !e.x.match(t) }
+
+func (e hasExpr) match(t Todo) bool {
+	value, ok := filterField(t, e.name)
+	return ok && (value != "" || !isFilterField(e.name))
+}
+
+func (e cmpExpr) match(t Todo) bool {
+	value, ok := filterField(t, e.name)
+	if !ok {
+		return e.op == "!="
+	}
+	switch e.op {
+	case "==":
+		return value == e.value
+	case "!=":
+		return value != e.value
+	case "~":
+		return globMatch(e.value, value)
+	case "=~":
+		return e.re.MatchString(value)
+	}
+	c, ok := compareValues(value, e.value)
+	if !ok {
+		return false
+	}
+	switch e.op {
+	case "<":
+		return c < 0
+	case "<=":
+		return c <= 0
+	case ">":
+		return c > 0
+	case ">=":
+		return c >= 0
+	default:
+		return false
+	}
+}
+
+// isFilterField reports whether the name is a field rather than an attribute.
+func isFilterField(name string) bool {
+	switch name {
+	case "path", "line", "keyword", "description", "kind", "deadline":
+		return true
+	default:
+		return false
+	}
+}
+
+// filterField returns the value of a field or attribute.
+func filterField(t Todo, name string) (string, bool) {
+	switch name {
+	case "path":
+		return path.Clean(strings.ReplaceAll(t.Location.File, "\\", "/")), true
+	case "line":
+		return strconv.Itoa(t.Location.Line), true
+	case "keyword":
+		if t.Keyword == "" {
+			return "TODO", true
+		}
+		return t.Keyword, true
+	case "description":
+		return t.Description, true
+	case "kind":
+		return t.Kind.String(), true
+	case "deadline":
+		value, _, ok := t.deadline()
+		return value, ok
+	default:
+		return t.Attribute(name)
+	}
+}
+
+// compareValues compares the values as dates, then numbers, then strings.
+// It reports false if only one of the values is a date.
+func compareValues(a, b string) (int, bool) {
+	x, xok := parseFilterTime(a)
+	y, yok := parseFilterTime(b)
+	if xok || yok {
+		return x.Compare(y), xok && yok
+	}
+	if x, err := strconv.ParseFloat(a, 64); err == nil {
+		if y, err := strconv.ParseFloat(b, 64); err == nil {
+			return cmp.Compare(x, y), true
+		}
+	}
+	return strings.Compare(a, b), true
+}
+
+// parseFilterTime parses a date (2006-01-02) or an RFC 3339 timestamp.
+func parseFilterTime(s string) (time.Time, bool) {
+	if t, err := time.Parse(time.DateOnly, s); err == nil {
+		return t, true
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, true
+	}
+	return time.Time{}, false
+}
+
+// globMatch reports whether the name matches the slash separated glob pattern.
+// A ** element matches any number of path elements.
+func globMatch(pattern, name string) bool {
+	return globMatchParts(strings.Split(pattern, "/"), strings.Split(name, "/"))
+}
+
+func globMatchParts(pattern, name []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			for i := len(name); i >= 0; i-- {
+				if globMatchParts(pattern[1:], name[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(name) == 0 {
+			return false
+		}
+		if ok, _ := path.Match(pattern[0], name[0]); !ok {
+			return false
+		}
+		pattern, name = pattern[1:], name[1:]
+	}
+	return len(name) == 0
+}
+
+// filterToken kinds.
+const (
+	tokEOF = iota
+	tokWord
+	tokString
+	tokOp
+)
+
+// filterToken is a lexical token in a filter expression.
+type filterToken struct {
+	kind  int
+	text  string
+	value string
+	pos   int
+}
+
+func (t filterToken) String() string {
+	if t.kind == tokEOF {
+		return "end of expression"
+	}
+	return strconv.Quote(t.text)
+}
+
+// filterParser is a recursive descent parser for filter expressions.
+type filterParser struct {
+	src string
+	pos int
+	tok filterToken
+	err error
+}
+
+// filterOps are the operators, longest first.
+var filterOps = []string{"&&", "||", "==", "!=", "<=", ">=", "=~", "<", ">", "~", "!", "(", ")"}
+
+// next reads the next token.
+func (p *filterParser) next() {
+	for p.pos < len(p.src) {
+		c, size := utf8.DecodeRuneInString(p.src[p.pos:])
+		if !unicode.IsSpace(c) {
+			break
+		}
+		p.pos += size
+	}
+	start := p.pos
+	if p.pos == len(p.src) {
+		p.tok = filterToken{kind: tokEOF, pos: start}
+		return
+	}
+	if c := p.src[p.pos]; c == '"' || c == '\'' {
+		p.pos++
+		for p.pos < len(p.src) && p.src[p.pos] != c {
+			if c == '"' && p.src[p.pos] == '\\' {
+				p.pos++
+			}
+			p.pos++
+		}
+		if p.pos >= len(p.src) {
+			p.err = fmt.Errorf("filter: unterminated string at %d", start)
+			p.tok = filterToken{kind: tokEOF, pos: start}
+			return
+		}
+		p.pos++
+		// single quoted strings are raw, which is convenient for regexps
+		text := p.src[start:p.pos]
+		value := text[1 : len(text)-1]
+		if c == '"' {
+			var err error
+			if value, err = strconv.Unquote(text); err != nil {
+				p.err = fmt.Errorf("filter: invalid string %s at %d", text, start)
+			}
+		}
+		p.tok = filterToken{kind: tokString, text: text, value: value, pos: start}
+		return
+	}
+	for _, op := range filterOps {
+		if strings.HasPrefix(p.src[p.pos:], op) {
+			p.pos += len(op)
+			p.tok = filterToken{kind: tokOp, text: op, pos: start}
+			return
+		}
+	}
+	for p.pos < len(p.src) {
+		c, size := utf8.DecodeRuneInString(p.src[p.pos:])
+		if !isFilterWordChar(c) {
+			break
+		}
+		p.pos += size
+	}
+	if p.pos == start {
+		_, size := utf8.DecodeRuneInString(p.src[p.pos:])
+		p.pos += size
+	}
+	text := p.src[start:p.pos]
+	p.tok = filterToken{kind: tokWord, text: text, value: text, pos: start}
+}
+
+// isFilterWordChar reports whether the character can be part of a bare word.
+// Bare words can be names, dates, numbers and simple paths.
+func isFilterWordChar(c rune) bool {
+	return unicode.IsLetter(c) || unicode.IsDigit(c) || strings.ContainsRune("_-.:/*", c)
+}
+
+// errorf returns an error at the current token.
+func (p *filterParser) errorf(format string, args ...any) error {
+	if p.err != nil {
+		return p.err
+	}
+	return fmt.Errorf("filter: %s at %d", fmt.Sprintf(format, args...), p.tok.pos)
+}
+
+// is reports whether the current token is the operator.
+func (p *filterParser) is(op string) bool {
+	return p.err == nil && p.tok.kind == tokOp && p.tok.text == op
+}
+
+// or parses: and { "||" and }
+func (p *filterParser) or() (filterExpr, error) {
+	x, err := p.and()
+	if err != nil {
+		return nil, err
+	}
+	for p.is("||") {
+		p.next()
+		y, err := p.and()
+		if err != nil {
+			return nil, err
+		}
+		x = orExpr{x, y}
+	}
+	return x, nil
+}
+
+// and parses: unary { "&&" unary }
+func (p *filterParser) and() (filterExpr, error) {
+	x, err := p.unary()
+	if err != nil {
+		return nil, err
+	}
+	for p.is("&&") {
+		p.next()
+		y, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		x = andExpr{x, y}
+	}
+	return x, nil
+}
+
+// unary parses: "!" unary | "(" or ")" | name [ op value ]
+func (p *filterParser) unary() (filterExpr, error) {
+	switch {
+	case p.is("!"):
+		p.next()
+		x, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		return notExpr{x}, nil
+	case p.is("("):
+		p.next()
+		x, err := p.or()
+		if err != nil {
+			return nil, err
+		}
+		if !p.is(")") {
+			return nil, p.errorf("expected \")\", got %s", p.tok)
+		}
+		p.next()
+		return x, nil
+	}
+	if p.err != nil || (p.tok.kind != tokWord && p.tok.kind != tokString) {
+		return nil, p.errorf("expected name, got %s", p.tok)
+	}
+	name := p.tok.value
+	p.next()
+	if p.tok.kind != tokOp {
+		return hasExpr{name: name}, nil
+	}
+	op := p.tok.text
+	switch op {
+	case "==", "!=", "<", "<=", ">", ">=", "~", "=~":
+	default:
+		return hasExpr{name: name}, nil
+	}
+	p.next()
+	if p.err != nil || (p.tok.kind != tokWord && p.tok.kind != tokString) {
+		return nil, p.errorf("expected value after %q, got %s", op, p.tok)
+	}
+	e := cmpExpr{name: name, op: op, value: p.tok.value}
+	if op == "=~" {
+		re, err := regexp.Compile(e.value)
+		if err != nil {
+			return nil, fmt.Errorf("filter: %w", err)
+		}
+		e.re = re
+	}
+	p.next()
+	return e, nil
+}
@@ -0,0 +1,148 @@
+package todo
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFilter(t *testing.T) {
+	todo := Todo{
+		Location:    Location{File: "pkg/api/server.go", Line: 12},
+		Keyword:     "FIXME",
+		Description: "handle timeouts",
+		Attributes: []Attribute{
+			{Key: "owner", Value: "alice"},
+			{Key: "deadline", Value: "2025-12-01"},
+			{Key: "priority", Value: "2"},
+			{Key: "wip"},
+			{Key: "team", Value: "équipe"},
+		},
+	}
+	tests := []struct {
+		expr string
+		want bool
+	}{
+		{expr: "", want: true},
+		{expr: "owner", want: true},
+		{expr: "wip", want: true},
+		{expr: "issue", want: false},
+		{expr: "!issue", want: true},
+		{expr: `owner == "alice"`, want: true},
+		{expr: "owner == bob", want: false},
+		{expr: "owner != bob", want: true},
+		{expr: "issue != 1", want: true},
+		{expr: "issue == 1", want: false},
+		{expr: "deadline < 2026-01-01", want: true},
+		{expr: "deadline >= 2026-01-01", want: false},
+		{expr: "deadline < 2025-12-01T12:00:00Z", want: true},
+		{expr: "deadline <= 2025-12-01", want: true},
+		{expr: "deadline == 2025-12-01", want: true},
+		{expr: "deadline < someday", want: false},
+		{expr: "deadline >= someday", want: false},
+		{expr: "owner < 2026-01-01", want: false},
+		{expr: "owner >= 2026-01-01", want: false},
+		{expr: "priority <= 10", want: true},
+		{expr: "line > 9", want: true},
+		{expr: `path ~ "pkg/**"`, want: true},
+		{expr: "path ~ pkg/*.go", want: false},
+		{expr: "path ~ **/*.go", want: true},
+		{expr: "path ~ cmd/**", want: false},
+		{expr: `description =~ '^handle\s'`, want: true},
+		{expr: "keyword == FIXME", want: true},
+		{expr: "team == équipe\u00a0", want: true},
+		{expr: "kind == text", want: true},
+		{expr: `owner == "alice" && deadline < 2026-01-01 && path ~ "pkg/**"`, want: true},
+		{expr: "owner == bob || priority == 2", want: true},
+		{expr: "!(owner == alice || wip)", want: false},
+		{expr: "owner == bob || owner == alice && issue", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			f := &Filter{}
+			if tt.expr != "" {
+				var err error
+				if f, err = ParseFilter(tt.expr); err != nil {
+					t.Fatalf("ParseFilter(%q) error: %v", tt.expr, err)
+				}
+			}
+			if got := f.Match(todo); got != tt.want {
+				t.Errorf("Match() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFilterDeadline(t *testing.T) {
+	todos := []Todo{
+		{Description: "deadline", Attributes: []Attribute{{Key: "deadline", Value: "2025-06-01"}}},
+		{Description: "due", Attributes: []Attribute{{Key: "due", Value: "2025-07-01"}}},
+		{Description: "invalid", Attributes: []Attribute{{Key: "deadline", Value: "someday"}, {Key: "due", Value: "2025-08-01"}}},
+		{Description: "none", Attributes: []Attribute{{Key: "deadline", Value: "someday"}}},
+	}
+	tests := []struct {
+		expr string
+		want []string
+	}{
+		{expr: "deadline", want: []string{"deadline", "due", "invalid"}},
+		{expr: "!deadline", want: []string{"none"}},
+		{expr: "deadline < 2025-07-15", want: []string{"deadline", "due"}},
+		{expr: "deadline == 2025-08-01", want: []string{"invalid"}},
+		{expr: "deadline > 2025-01-01T00:00:00Z", want: []string{"deadline", "due", "invalid"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			f, err := ParseFilter(tt.expr)
+			if err != nil {
+				t.Fatalf("ParseFilter(%q) error: %v", tt.expr, err)
+			}
+			var got []string
+			for _, todo := range f.Apply(todos) {
+				got = append(got, todo.Description)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Apply() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseFilterError(t *testing.T) {
+	tests := []string{
+		"",
+		"owner ==",
+		"owner = alice",
+		"(owner",
+		`owner == "alice`,
+		`owner "alice`,
+		"description =~ '('",
+		"&& owner",
+	}
+	for _, expr := range tests {
+		t.Run(expr, func(t *testing.T) {
+			if _, err := ParseFilter(expr); err == nil {
+				t.Errorf("ParseFilter(%q) expected error", expr)
+			}
+		})
+	}
+}
+
+func TestGlobMatch(t *testing.T) {
+	tests := []struct {
+		pattern string
+		name    string
+		want    bool
+	}{
+		{pattern: "*.go", name: "main.go", want: true},
+		{pattern: "*.go", name: "cmd/main.go", want: false},
+		{pattern: "**", name: "cmd/main.go", want: true},
+		{pattern: "cmd/**", name: "cmd", want: true},
+		{pattern: "**/main.go", name: "main.go", want: true},
+		{pattern: "a/**/b/*.go", name: "a/x/y/b/c.go", want: true},
+		{pattern: "a/**/b/*.go", name: "a/x/y/c.go", want: false},
+	}
+	for _, tt := range tests {
+		if got := globMatch(tt.pattern, tt.name); got != tt.want {
+			t.Errorf("globMatch(%q, %q) = %v, want %v", tt.pattern, tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package todo
+
+import (
+	"cmp"
+	"slices"
+	"strings"
+)
+
+// FormatOptions configures Format.
+type FormatOptions struct {
+	// KeyOrder lists attribute keys in the order they should appear.
+	// Attributes which aren't listed are placed after the listed ones
+	// in their original order.
+	KeyOrder []string
+	// KeywordCase is "upper" or "lower" to change the case of the keyword.
+	// The keyword is left unchanged if it's empty.
+	KeywordCase string
+}
+
+// Format returns the TODO in canonical form. Values are only quoted when necessary,
+// and the attributes are sorted using the KeyOrder. The Raw text is cleared,
+// so the canonical text is produced by both Todo.String and Todo.Text.
+func (t Todo) Format(opt FormatOptions) Todo {
+	t.Raw = ""
+	switch opt.KeywordCase {
+	case "upper":
+		t.Keyword = strings.ToUpper(t.Keyword)
+	case "lower":
+		t.Keyword = strings.ToLower(t.Keyword)
+	}
+	t.Attributes = slices.Clone(t.Attributes)
+	for i, a := range t.Attributes {
+		t.Attributes[i].Quote = needsQuote(a.Value)
+		t.Attributes[i].Raw = ""
+	}
+	rank := func(key string) int {
+		if i := slices.Index(opt.KeyOrder, key); i >= 0 {
+			return i
+		}
+		return len(opt.KeyOrder)
+	}
+	slices.SortStableFunc(t.Attributes, func(a, b Attribute) int {
+		return cmp.Compare(rank(a.Key), rank(b.Key))
+	})
+	return t
+}
+
+// FormatSource formats all the TODOs in the source.
+// Only the TODO text is changed, the rest of the source is preserved.
+func (r *Registry) FormatSource(file string, source []byte, opt FormatOptions) ([]byte, error) {
+	todos, err := r.Parse(file, source)
+	if err != nil {
+		return nil, err
+	}
+	for i, t := range todos {
+		todos[i] = t.Format(opt)
+	}
+	return Rewrite(source, todos)
+}
+
+// FormatSource formats all the TODOs in the source using the default registry.
+func FormatSource(file string, source []byte, opt FormatOptions) ([]byte, error) {
+	return DefaultRegistry.FormatSource(file, source, opt)
+}
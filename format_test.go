@@ -0,0 +1,108 @@
+package todo
+
+import "testing"
+
+func TestFormatSource(t *testing.T) {
+	tests := []struct {
+		name   string
+		file   string
+		source string
+		opt    FormatOptions
+		want   string
+	}{
+		{
+			name:   "spacing",
+			file:   "main.go",
+			source: "//  TODO (owner = bob,issue=1 ):fix this  \n",
+			want:   "//  TODO(owner=bob, issue=1): fix this  \n",
+		},
+		{
+			name:   "quoting",
+			file:   "main.go",
+			source: "// TODO(owner=\"bob\", deadline=\"June 2025\"): fix this\n",
+			want:   "// TODO(owner=bob, deadline=\"June 2025\"): fix this\n",
+		},
+		{
+			name:   "key order",
+			file:   "main.go",
+			source: "// TODO(issue=1, other, owner=bob, created=2025-03-09): fix this\n",
+			opt:    FormatOptions{KeyOrder: []string{"created", "owner"}},
+			want:   "// TODO(created=2025-03-09, owner=bob, issue=1, other): fix this\n",
+		},
+		{
+			name:   "empty description",
+			file:   "main.c",
+			source: "/* TODO(): */\n",
+			want:   "/* TODO: */\n",
+		},
+		{
+			name:   "non-ascii",
+			file:   "main.go",
+			source: "// TODO (owner=bob): voilà\n// TODO(owner=bob):  Å \n",
+			want:   "// TODO(owner=bob): voilà\n// TODO(owner=bob): Å \n",
+		},
+		{
+			name:   "lower case",
+			file:   "main.go",
+			source: "// TODO(owner=bob): fix this\n",
+			opt:    FormatOptions{KeywordCase: "lower"},
+			want:   "// todo(owner=bob): fix this\n",
+		},
+		{
+			name:   "upper case",
+			file:   "main.py",
+			source: "# Todo: fix this\n",
+			opt:    FormatOptions{KeywordCase: "upper"},
+			want:   "# TODO: fix this\n",
+		},
+		{
+			name:   "unchanged",
+			file:   "main.py",
+			source: "def main():\n    # TODO(owner=bob): fix this\n    pass\n",
+			want:   "def main():\n    # TODO(owner=bob): fix this\n    pass\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := FormatSource(tt.file, []byte(tt.source), tt.opt)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("FormatSource() = %q, want %q", got, tt.want)
+			}
+			again, err := FormatSource(tt.file, got, tt.opt)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(again) != string(got) {
+				t.Errorf("FormatSource() is not idempotent: %q", again)
+			}
+		})
+	}
+}
+
+func TestFormatKeywordCase(t *testing.T) {
+	todo := Todo{Keyword: "Fixme", Description: "fix"}
+	if got := todo.Format(FormatOptions{KeywordCase: "upper"}).String(); got != "FIXME: fix" {
+		t.Errorf("Format() = %q, want %q", got, "FIXME: fix")
+	}
+	if got := todo.Format(FormatOptions{KeywordCase: "lower"}).String(); got != "fixme: fix" {
+		t.Errorf("Format() = %q, want %q", got, "fixme: fix")
+	}
+}
+
+func TestFormatKeywordCaseRoundTrip(t *testing.T) {
+	source := "// TODO(owner=bob): fix this\n"
+	lower, err := FormatSource("main.go", []byte(source), FormatOptions{KeywordCase: "lower"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	upper, err := FormatSource("main.go", lower, FormatOptions{KeywordCase: "upper"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(upper) != source {
+		t.Errorf("FormatSource() = %q, want %q", upper, source)
+	}
+}
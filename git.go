@@ -0,0 +1,168 @@
+package todo
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Commit identifies the commit which introduced a line.
+type Commit struct {
+	Hash        string
+	Author      string
+	AuthorEmail string
+	AuthorTime  time.Time
+}
+
+// Blame sets the Commit field of each TODO using git blame.
+// The Commit of TODOs on uncommitted lines, or in untracked files, is set to nil.
+// The git command must be installed and the files must be in a git repository.
+func Blame(todos []Todo) error {
+	files := map[string][]int{}
+	var order []string
+	for i, t := range todos {
+		if _, ok := files[t.Location.File]; !ok {
+			order = append(order, t.Location.File)
+		}
+		files[t.Location.File] = append(files[t.Location.File], i)
+	}
+	for _, file := range order {
+		var lines []int
+		for _, i := range files[file] {
+			lines = append(lines, todos[i].Location.Line)
+		}
+		commits, err := blameLines(file, lines)
+		if err != nil {
+			return err
+		}
+		for _, i := range files[file] {
+			todos[i].Commit = commits[todos[i].Location.Line]
+		}
+	}
+	return nil
+}
+
+// blameLines returns the commits which introduced the given lines of the file.
+// Uncommitted lines are not included in the result.
+func blameLines(file string, lines []int) (map[int]*Commit, error) {
+	args := []string{"blame", "--porcelain"}
+	for _, line := range lines {
+		args = append(args, "-L", fmt.Sprintf("%d,%d", line, line))
+	}
+	dir, name := filepath.Split(file)
+	if ok, err := tracked(dir, name); err != nil || !ok {
+		return map[int]*Commit{}, err
+	}
+	args = append(args, "--", name)
+	out, err := git(dir, args...)
+	if err != nil {
+		return nil, err
+	}
+	return parseBlame(out)
+}
+
+// tracked reports whether the file is in the git index.
+func tracked(dir, name string) (bool, error) {
+	_, err := git(dir, "ls-files", "--error-unmatch", "--", name)
+	// git exits with status 1 when the file isn't tracked
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && exitErr.ExitCode() == 1 {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// parseBlame parses the output of git blame --porcelain and returns
+// the commits keyed by their final line number.
+func parseBlame(out []byte) (map[int]*Commit, error) {
+	result := map[int]*Commit{}
+	commits := map[string]*Commit{}
+	var current *Commit
+	sc := bufio.NewScanner(bytes.NewReader(out))
+	for sc.Scan() {
+		line := sc.Text()
+		if strings.HasPrefix(line, "\t") {
+			continue
+		}
+		key, value, _ := strings.Cut(line, " ")
+		switch key {
+		case "author":
+			current.Author = value
+		case "author-mail":
+			current.AuthorEmail = strings.Trim(value, "<>")
+		case "author-time":
+			sec, err := strconv.ParseInt(value, 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid author-time: %q", value)
+			}
+			current.AuthorTime = time.Unix(sec, 0).UTC()
+		default:
+			// commit header: <hash> <orig-line> <final-line> [<num-lines>]
+			fields := strings.Fields(line)
+			if len(fields) < 3 || !isHash(fields[0]) {
+				continue
+			}
+			final, err := strconv.Atoi(fields[2])
+			if err != nil {
+				return nil, fmt.Errorf("invalid blame header: %q", line)
+			}
+			c, ok := commits[fields[0]]
+			if !ok {
+				c = &Commit{Hash: fields[0]}
+				commits[fields[0]] = c
+			}
+			current = c
+			// uncommitted lines use the zero hash
+			if strings.Trim(c.Hash, "0") != "" {
+				result[final] = c
+			}
+		}
+	}
+	return result, sc.Err()
+}
+
+// isHash reports whether s looks like a full SHA-1 or SHA-256 object name.
+func isHash(s string) bool {
+	if len(s) != 40 && len(s) != 64 {
+		return false
+	}
+	for _, r := range s {
+		if !strings.ContainsRune("0123456789abcdef", r) {
+			return false
+		}
+	}
+	return true
+}
+
+// inRepository reports whether the directory is inside a git work tree.
+func inRepository(dir string) (bool, error) {
+	out, err := git(dir, "rev-parse", "--is-inside-work-tree")
+	// git exits with an error status outside of a repository
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return strings.TrimSpace(string(out)) == "true", nil
+}
+
+// git runs a git command in the given directory and returns its output.
+func git(dir string, args ...string) ([]byte, error) {
+	var stderr bytes.Buffer
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("git %s: %w: %s", args[0], err, strings.TrimSpace(stderr.String()))
+	}
+	return out, nil
+}
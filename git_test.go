@@ -0,0 +1,223 @@
+package todo
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// testRepo is a temporary git repository.
+type testRepo struct {
+	t   *testing.T
+	dir string
+}
+
+// newTestRepo creates an empty git repository in a temporary directory.
+// The test is skipped if git is not installed.
+func newTestRepo(t *testing.T) *testRepo {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	r := &testRepo{t: t, dir: t.TempDir()}
+	r.git("init", "-q", "-b", "main")
+	return r
+}
+
+// git runs a git command in the repository.
+func (r *testRepo) git(args ...string) string {
+	r.t.Helper()
+	cmd := exec.Command("git", args...)
+	cmd.Dir = r.dir
+	cmd.Env = append(os.Environ(),
+		"GIT_CONFIG_GLOBAL=/dev/null",
+		"GIT_CONFIG_SYSTEM=/dev/null",
+		"GIT_AUTHOR_NAME=Alice",
+		"GIT_AUTHOR_EMAIL=alice@example.com",
+		"GIT_COMMITTER_NAME=Alice",
+		"GIT_COMMITTER_EMAIL=alice@example.com",
+		"GIT_AUTHOR_DATE=2025-03-09T12:00:00Z",
+		"GIT_COMMITTER_DATE=2025-03-09T12:00:00Z",
+	)
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		r.t.Fatalf("git %v: %v: %s", args, err, out)
+	}
+	return string(out)
+}
+
+// write writes a file in the repository.
+func (r *testRepo) write(name, content string) string {
+	r.t.Helper()
+	path := filepath.Join(r.dir, name)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		r.t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		r.t.Fatal(err)
+	}
+	return path
+}
+
+// commit stages all changes and commits them.
+func (r *testRepo) commit(message string) {
+	r.t.Helper()
+	r.git("add", "-A")
+	r.git("commit", "-q", "-m", message)
+}
+
+func TestBlame(t *testing.T) {
+	repo := newTestRepo(t)
+	repo.write("main.go", "package main\n\n// TODO: committed\n")
+	repo.commit("initial")
+	path := repo.write("main.go", "package main\n\n// TODO: committed\n// TODO: uncommitted\n")
+	untracked := repo.write("other.go", "// TODO: untracked\n")
+	var todos []Todo
+	for _, file := range []string{path, untracked} {
+		source, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		parsed, err := Parse(file, source)
+		if err != nil {
+			t.Fatal(err)
+		}
+		todos = append(todos, parsed...)
+	}
+	if len(todos) != 3 {
+		t.Fatalf("Parse() = %v, want 3 todos", todos)
+	}
+	if err := Blame(todos); err != nil {
+		t.Fatalf("Blame() error = %v", err)
+	}
+	c := todos[0].Commit
+	if c == nil {
+		t.Fatalf("Blame() committed TODO has no commit")
+	}
+	if c.Author != "Alice" || c.AuthorEmail != "alice@example.com" || len(c.Hash) != 40 {
+		t.Errorf("Blame() commit = %+v", c)
+	}
+	if want := time.Date(2025, 3, 9, 12, 0, 0, 0, time.UTC); !c.AuthorTime.Equal(want) {
+		t.Errorf("Blame() author time = %v, want %v", c.AuthorTime, want)
+	}
+	if todos[1].Commit != nil {
+		t.Errorf("Blame() uncommitted TODO commit = %+v, want nil", todos[1].Commit)
+	}
+	if todos[2].Commit != nil {
+		t.Errorf("Blame() untracked TODO commit = %+v, want nil", todos[2].Commit)
+	}
+	outside := []Todo{{Location: Location{File: filepath.Join(t.TempDir(), "main.go"), Line: 1}}}
+	if err := Blame(outside); err == nil {
+		t.Error("Blame() outside of a repository expected error")
+	}
+}
+
+func TestParseRevision(t *testing.T) {
+	repo := newTestRepo(t)
+	repo.write("main.go", "package main\n\n// TODO: first\n")
+	repo.write("lib/lib.py", "# TODO(owner=alice): second\n")
+	repo.write("data.bin", "TODO: binary\x00")
+	repo.commit("initial")
+	repo.git("tag", "v1")
+	repo.write("main.go", "package main\n\n// TODO: changed\n")
+	repo.commit("second")
+	repo.write("main.go", "package main\n\n// TODO: uncommitted\n")
+	tests := []struct {
+		rev   string
+		paths []string
+		want  []string
+	}{
+		{
+			rev: "v1",
+			want: []string{
+				"lib/lib.py:1 TODO(owner=alice): second",
+				"main.go:3 TODO: first",
+			},
+		},
+		{
+			rev:   "HEAD",
+			paths: []string{"main.go"},
+			want: []string{
+				"main.go:3 TODO: changed",
+			},
+		},
+		{
+			rev:   "v1",
+			paths: []string{"lib"},
+			want: []string{
+				"lib/lib.py:1 TODO(owner=alice): second",
+			},
+		},
+	}
+	r, err := OpenRepository(filepath.Join(repo.dir, "lib"))
+	if err != nil {
+		t.Fatalf("OpenRepository() error = %v", err)
+	}
+	defer r.Close()
+	for _, tt := range tests {
+		t.Run(tt.rev, func(t *testing.T) {
+			todos, err := r.Parse(tt.rev, tt.paths...)
+			if err != nil {
+				t.Fatalf("Parse(%q) error = %v", tt.rev, err)
+			}
+			var got []string
+			for _, todo := range todos {
+				got = append(got, fmt.Sprintf("%s %s", todo.Location, todo))
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Parse(%q) = %q, want %q", tt.rev, got, tt.want)
+			}
+		})
+	}
+	if _, err := r.Parse("missing"); err == nil {
+		t.Errorf("Parse(missing) expected error")
+	}
+	source, err := r.ReadFile("v1", "main.go")
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	if want := "package main\n\n// TODO: first\n"; string(source) != want {
+		t.Errorf("ReadFile() = %q, want %q", source, want)
+	}
+}
+
+func TestParseRevisionSubdir(t *testing.T) {
+	repo := newTestRepo(t)
+	repo.write("main.go", "package main\n\n// TODO: root\n")
+	repo.write("lib/lib.py", "# TODO: lib\n")
+	repo.commit("initial")
+	dir := filepath.Join(repo.dir, "lib")
+	tests := []struct {
+		paths []string
+		want  []string
+	}{
+		{
+			want: []string{"lib/lib.py:1 TODO: lib", "main.go:3 TODO: root"},
+		},
+		{
+			paths: []string{"lib.py"},
+			want:  []string{"lib/lib.py:1 TODO: lib"},
+		},
+		{
+			paths: []string{"../main.go"},
+			want:  []string{"main.go:3 TODO: root"},
+		},
+	}
+	for _, tt := range tests {
+		todos, err := ParseRevision(dir, "HEAD", tt.paths...)
+		if err != nil {
+			t.Fatalf("ParseRevision(%q) error = %v", tt.paths, err)
+		}
+		var got []string
+		for _, todo := range todos {
+			got = append(got, fmt.Sprintf("%s %s", todo.Location, todo))
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ParseRevision(%q) = %q, want %q", tt.paths, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,162 @@
+package todo
+
+import (
+	"cmp"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Group is a set of TODOs which have the same value for a key.
+type Group struct {
+	Name  string
+	Todos []Todo
+}
+
+// GroupKey returns the value of the key for the TODO. The key is file,
+// dir (or directory), keyword, or the name of an attribute.
+// The value is empty if the TODO doesn't have the attribute.
+func GroupKey(t Todo, key string) string {
+	switch key {
+	case "file":
+		return t.Location.File
+	case "dir", "directory":
+		return filepath.Dir(t.Location.File)
+	case "keyword":
+		return cmp.Or(t.Keyword, "TODO")
+	default:
+		value, _ := t.Attribute(key)
+		return value
+	}
+}
+
+// GroupBy groups the TODOs by the value of the key.
+// The groups are sorted by name, with the unnamed group last,
+// and the TODOs in each group keep their order.
+func GroupBy(todos []Todo, key string) []Group {
+	var groups []Group
+	index := map[string]int{}
+	for _, t := range todos {
+		name := GroupKey(t, key)
+		i, ok := index[name]
+		if !ok {
+			i = len(groups)
+			index[name] = i
+			groups = append(groups, Group{Name: name})
+		}
+		groups[i].Todos = append(groups[i].Todos, t)
+	}
+	slices.SortFunc(groups, func(a, b Group) int {
+		if (a.Name == "") != (b.Name == "") {
+			if a.Name == "" {
+				return 1
+			}
+			return -1
+		}
+		return strings.Compare(a.Name, b.Name)
+	})
+	return groups
+}
+
+// Created returns the value of the created attribute as a time.
+// If the TODO doesn't have one, the time of its Commit is used.
+func (t Todo) Created() (time.Time, bool) {
+	if value, ok := t.Attribute("created"); ok {
+		if d, err := time.Parse(time.DateOnly, value); err == nil {
+			return d, true
+		}
+		if d, err := time.Parse(time.RFC3339, value); err == nil {
+			return d, true
+		}
+	}
+	if t.Commit != nil {
+		return t.Commit.AuthorTime, true
+	}
+	return time.Time{}, false
+}
+
+// Priority returns the rank of the priority attribute, where 0 is the most urgent.
+// The values critical, high, medium and low, or p0 to p4, or 0 to 4 are recognized.
+func (t Todo) Priority() (int, bool) {
+	priority, ok := t.Attribute("priority")
+	if !ok {
+		return 0, false
+	}
+	switch strings.ToLower(priority) {
+	case "critical", "p0", "0":
+		return 0, true
+	case "high", "p1", "1":
+		return 1, true
+	case "medium", "p2", "2":
+		return 2, true
+	case "low", "p3", "3":
+		return 3, true
+	case "p4", "4":
+		return 4, true
+	default:
+		return 0, false
+	}
+}
+
+// SortBy sorts the TODOs by each of the keys in turn. The keys are path,
+// line, deadline, priority and created, and a key prefixed with - is sorted
+// in descending order. TODOs without a deadline, priority or created time
+// are sorted last. The sort is stable, so TODOs which are equal keep their order.
+func SortBy(todos []Todo, keys ...string) error {
+	type sortKey struct {
+		compare func(a, b Todo, desc bool) int
+		desc    bool
+	}
+	var sortKeys []sortKey
+	for _, key := range keys {
+		name, desc := strings.CutPrefix(key, "-")
+		compare, ok := sortCompares[name]
+		if !ok {
+			return fmt.Errorf("unknown sort key: %q", key)
+		}
+		sortKeys = append(sortKeys, sortKey{compare: compare, desc: desc})
+	}
+	slices.SortStableFunc(todos, func(a, b Todo) int {
+		for _, k := range sortKeys {
+			if c := k.compare(a, b, k.desc); c != 0 {
+				return c
+			}
+		}
+		return 0
+	})
+	return nil
+}
+
+// sortCompares are the comparison functions for each sort key.
+var sortCompares = map[string]func(a, b Todo, desc bool) int{
+	"path": compareBy(func(t Todo) (Location, bool) { return t.Location, true }, func(x, y Location) int {
+		return cmp.Or(strings.Compare(x.File, y.File), cmp.Compare(x.Line, y.Line))
+	}),
+	"line":     compareBy(func(t Todo) (int, bool) { return t.Location.Line, true }, cmp.Compare[int]),
+	"deadline": compareBy(Todo.Deadline, time.Time.Compare),
+	"priority": compareBy(Todo.Priority, cmp.Compare[int]),
+	"created":  compareBy(Todo.Created, time.Time.Compare),
+}
+
+// compareBy returns a comparison of the values returned by get.
+// TODOs without a value are sorted last, even in descending order.
+func compareBy[T any](get func(Todo) (T, bool), compare func(x, y T) int) func(a, b Todo, desc bool) int {
+	return func(a, b Todo, desc bool) int {
+		x, xok := get(a)
+		y, yok := get(b)
+		switch {
+		case !xok && !yok:
+			return 0
+		case !xok:
+			return 1
+		case !yok:
+			return -1
+		case desc:
+			return compare(y, x)
+		default:
+			return compare(x, y)
+		}
+	}
+}
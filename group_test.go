@@ -0,0 +1,92 @@
+package todo
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestGroupBy(t *testing.T) {
+	todos := ParseText("pkg/a.go", []byte("TODO(owner=bob): a\nTODO: b\nTODO(owner=alice): c\nTODO(owner=bob): d\n"))
+	todos[1].Keyword = "FIXME"
+	tests := []struct {
+		key  string
+		want map[string][]string
+		keys []string
+	}{
+		{
+			key:  "owner",
+			keys: []string{"alice", "bob", ""},
+			want: map[string][]string{"alice": {"c"}, "bob": {"a", "d"}, "": {"b"}},
+		},
+		{
+			key:  "keyword",
+			keys: []string{"FIXME", "TODO"},
+			want: map[string][]string{"FIXME": {"b"}, "TODO": {"a", "c", "d"}},
+		},
+		{
+			key:  "dir",
+			keys: []string{"pkg"},
+			want: map[string][]string{"pkg": {"a", "b", "c", "d"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			groups := GroupBy(todos, tt.key)
+			var keys []string
+			got := map[string][]string{}
+			for _, g := range groups {
+				keys = append(keys, g.Name)
+				for _, todo := range g.Todos {
+					got[g.Name] = append(got[g.Name], todo.Description)
+				}
+			}
+			if !reflect.DeepEqual(keys, tt.keys) {
+				t.Errorf("GroupBy() names = %q, want %q", keys, tt.keys)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("GroupBy() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSortBy(t *testing.T) {
+	source := []byte("TODO(priority=low, created=2024-05-01): a\n" +
+		"TODO(deadline=2025-01-01): b\n" +
+		"TODO(priority=high, deadline=2024-06-01, created=2023-01-01): c\n" +
+		"TODO(priority=p2): d\n")
+	todos := ParseText("a.go", source)
+	todos = append(todos, ParseText("0.go", []byte("TODO: e\n"))...)
+	todos[4].Commit = &Commit{AuthorTime: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	tests := []struct {
+		keys []string
+		want string
+	}{
+		{keys: []string{"path"}, want: "eabcd"},
+		{keys: []string{"-line", "path"}, want: "dcbea"},
+		{keys: []string{"deadline"}, want: "cbade"},
+		{keys: []string{"-deadline"}, want: "bcade"},
+		{keys: []string{"priority"}, want: "cdabe"},
+		{keys: []string{"created"}, want: "ceabd"},
+		{keys: []string{"-created"}, want: "aecbd"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.keys[0], func(t *testing.T) {
+			sorted := append([]Todo(nil), todos...)
+			if err := SortBy(sorted, tt.keys...); err != nil {
+				t.Fatal(err)
+			}
+			var got string
+			for _, todo := range sorted {
+				got += todo.Description
+			}
+			if got != tt.want {
+				t.Errorf("SortBy(%q) = %q, want %q", tt.keys, got, tt.want)
+			}
+		})
+	}
+	if err := SortBy(todos, "owner"); err == nil {
+		t.Errorf("SortBy(%q) expected error", "owner")
+	}
+}
@@ -0,0 +1,117 @@
+package todo
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Revision is a commit in the history of a branch.
+type Revision struct {
+	Hash string
+	Time time.Time
+}
+
+// Log returns the first-parent history of rev, oldest first.
+// The Time of each revision is the committer date.
+func (r *Repository) Log(rev string) ([]Revision, error) {
+	out, err := git(r.dir, "log", "--first-parent", "--reverse", "--format=%H %ct", rev, "--")
+	if err != nil {
+		return nil, err
+	}
+	var revs []Revision
+	for line := range strings.Lines(string(out)) {
+		hash, ts, ok := strings.Cut(strings.TrimSpace(line), " ")
+		if !ok {
+			continue
+		}
+		sec, err := strconv.ParseInt(ts, 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		revs = append(revs, Revision{Hash: hash, Time: time.Unix(sec, 0).UTC()})
+	}
+	return revs, nil
+}
+
+// Lifetime records the revisions in which a TODO was present.
+type Lifetime struct {
+	// Todo is the most recent version of the TODO.
+	Todo      Todo
+	FirstSeen Revision
+	LastSeen  Revision
+	// Resolved is the revision which removed the TODO.
+	// It is nil if the TODO is still present.
+	Resolved *Revision
+}
+
+// Duration returns how long the TODO existed. For TODOs which
+// haven't been resolved, this is the time until it was last seen.
+func (l Lifetime) Duration() time.Duration {
+	if l.Resolved != nil {
+		return l.Resolved.Time.Sub(l.FirstSeen.Time)
+	}
+	return l.LastSeen.Time.Sub(l.FirstSeen.Time)
+}
+
+// Tracker follows the identity of TODOs across consecutive revisions.
+// TODOs are matched using the same rules as Diff, so a TODO keeps its
+// identity when it moves within a file or when its attributes change.
+type Tracker struct {
+	current   []Todo
+	ids       []int
+	lifetimes []Lifetime
+}
+
+// Add records the TODOs present in the next revision.
+func (t *Tracker) Add(rev Revision, todos []Todo) {
+	matches := matchTodos(t.current, todos)
+	ids := make([]int, len(todos))
+	seen := make([]bool, len(t.current))
+	for i, m := range matches {
+		if m.base < 0 {
+			ids[i] = len(t.lifetimes)
+			t.lifetimes = append(t.lifetimes, Lifetime{
+				Todo:      todos[i],
+				FirstSeen: rev,
+				LastSeen:  rev,
+			})
+			continue
+		}
+		seen[m.base] = true
+		ids[i] = t.ids[m.base]
+		l := &t.lifetimes[ids[i]]
+		l.Todo = todos[i]
+		l.LastSeen = rev
+	}
+	for j, ok := range seen {
+		if !ok {
+			t.lifetimes[t.ids[j]].Resolved = &rev
+		}
+	}
+	t.current = todos
+	t.ids = ids
+}
+
+// Lifetimes returns the lifetimes of all the TODOs seen so far,
+// in the order they were first seen.
+func (t *Tracker) Lifetimes() []Lifetime {
+	return t.lifetimes
+}
+
+// AverageLifetime returns the mean duration of the resolved TODOs.
+// It reports false if no TODOs have been resolved.
+func (t *Tracker) AverageLifetime() (time.Duration, bool) {
+	var total time.Duration
+	var n int
+	for _, l := range t.lifetimes {
+		if l.Resolved != nil {
+			total += l.Duration()
+			n++
+		}
+	}
+	if n == 0 {
+		return 0, false
+	}
+	return total / time.Duration(n), true
+}
@@ -0,0 +1,96 @@
+package todo
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestLog(t *testing.T) {
+	repo := newTestRepo(t)
+	repo.write("main.go", "package main\n")
+	repo.commit("first")
+	repo.write("main.go", "package main\n\n// TODO: second\n")
+	repo.commit("second")
+	r, err := OpenRepository(repo.dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	revs, err := r.Log("main")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		repo.git("rev-parse", "main~1"),
+		repo.git("rev-parse", "main"),
+	}
+	if len(revs) != len(want) {
+		t.Fatalf("Log() returned %d revisions, want %d", len(revs), len(want))
+	}
+	for i, rev := range revs {
+		if rev.Hash+"\n" != want[i] {
+			t.Errorf("Log()[%d].Hash = %q, want %q", i, rev.Hash, want[i])
+		}
+		if !rev.Time.Equal(time.Date(2025, 3, 9, 12, 0, 0, 0, time.UTC)) {
+			t.Errorf("Log()[%d].Time = %v", i, rev.Time)
+		}
+	}
+}
+
+func TestTracker(t *testing.T) {
+	todo := func(line int, text string) Todo {
+		t.Helper()
+		parsed, ok := parseLine([]byte(text), defaultKeywords)
+		if !ok {
+			t.Fatalf("invalid todo: %q", text)
+		}
+		parsed.Location = Location{File: "a.go", Line: line}
+		return parsed
+	}
+	day := func(n int) Revision {
+		return Revision{
+			Hash: string(rune('a' + n)),
+			Time: time.Date(2025, 3, n, 0, 0, 0, 0, time.UTC),
+		}
+	}
+	var tracker Tracker
+	tracker.Add(day(1), []Todo{todo(1, "TODO: one"), todo(2, "TODO: two")})
+	tracker.Add(day(2), []Todo{todo(5, "TODO: one"), todo(6, "TODO(owner=bob): two")})
+	tracker.Add(day(4), []Todo{todo(6, "TODO(owner=bob): two"), todo(7, "TODO: three")})
+	type result struct {
+		Todo      string
+		FirstSeen string
+		LastSeen  string
+		Resolved  string
+		Duration  time.Duration
+	}
+	var got []result
+	for _, l := range tracker.Lifetimes() {
+		r := result{
+			Todo:      l.Todo.String(),
+			FirstSeen: l.FirstSeen.Hash,
+			LastSeen:  l.LastSeen.Hash,
+			Duration:  l.Duration(),
+		}
+		if l.Resolved != nil {
+			r.Resolved = l.Resolved.Hash
+		}
+		got = append(got, r)
+	}
+	want := []result{
+		{Todo: "TODO: one", FirstSeen: "b", LastSeen: "c", Resolved: "e", Duration: 72 * time.Hour},
+		{Todo: "TODO(owner=bob): two", FirstSeen: "b", LastSeen: "e", Duration: 72 * time.Hour},
+		{Todo: "TODO: three", FirstSeen: "e", LastSeen: "e"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Lifetimes() = %+v, want %+v", got, want)
+	}
+	if avg, ok := tracker.AverageLifetime(); !ok || avg != 72*time.Hour {
+		t.Errorf("AverageLifetime() = %v, %v, want %v", avg, ok, 72*time.Hour)
+	}
+	var empty Tracker
+	if _, ok := empty.AverageLifetime(); ok {
+		t.Error("AverageLifetime() of an empty tracker reported ok")
+	}
+}
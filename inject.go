@@ -0,0 +1,129 @@
+package todo
+
+import (
+	"cmp"
+	"slices"
+	"strings"
+
+	treesitter "github.com/tree-sitter/go-tree-sitter"
+)
+
+// maxInjectionDepth limits how deeply injected languages may be nested.
+const maxInjectionDepth = 8
+
+// injectedRegion is a set of source ranges written in a single language.
+type injectedRegion struct {
+	lang   *LanguageOptions
+	ranges []treesitter.Range
+}
+
+// parseInjections runs the language's injection query over the tree and
+// parses the TODO comments in each of the injected regions.
+//
+// The following captures and properties are supported:
+//
+//   - @injection.content captures the embedded region.
+//   - @injection.language captures the name of the embedded language.
+//   - #set! injection.language sets a static embedded language name.
+//     It's also used when the captured language isn't registered.
+//   - #set! injection.combined parses all matching regions as a single document.
+//
+// Language names are resolved using the registered names and aliases.
+// Regions with unknown languages are ignored.
+func (r *Registry) parseInjections(file string, source []byte, opt *LanguageOptions, tree *treesitter.Tree, ranges []treesitter.Range, depth int) ([]Todo, error) {
+	if depth >= maxInjectionDepth {
+		return nil, nil
+	}
+	query := opt.injections
+	var regions []*injectedRegion
+	combined := map[*LanguageOptions]*injectedRegion{}
+	cursor := treesitter.NewQueryCursor()
+	defer cursor.Close()
+	matches := cursor.Matches(query, tree.RootNode(), source)
+	for {
+		m := matches.Next()
+		if m == nil {
+			break
+		}
+		var name, fallback string
+		var isCombined bool
+		for _, prop := range query.PropertySettings(m.PatternIndex) {
+			switch prop.Key {
+			case "injection.language":
+				if prop.Value != nil {
+					name = *prop.Value
+					fallback = name
+				}
+			case "injection.combined":
+				isCombined = true
+			}
+		}
+		var content []treesitter.Range
+		for _, c := range m.Captures {
+			switch query.CaptureNames()[c.Index] {
+			case "injection.language":
+				name = strings.TrimSpace(c.Node.Utf8Text(source))
+			case "injection.content":
+				content = append(content, clipRanges(c.Node.Range(), ranges)...)
+			}
+		}
+		lang, ok := r.Lookup(name)
+		if !ok && fallback != "" {
+			lang, ok = r.Lookup(fallback)
+		}
+		if !ok || len(content) == 0 {
+			continue
+		}
+		if !isCombined {
+			regions = append(regions, &injectedRegion{lang: lang, ranges: content})
+			continue
+		}
+		region, ok := combined[lang]
+		if !ok {
+			region = &injectedRegion{lang: lang}
+			combined[lang] = region
+			regions = append(regions, region)
+		}
+		region.ranges = append(region.ranges, content...)
+	}
+	var todos []Todo
+	for _, region := range regions {
+		slices.SortFunc(region.ranges, func(a, b treesitter.Range) int {
+			return cmp.Compare(a.StartByte, b.StartByte)
+		})
+		injected, err := r.parseRanges(file, source, region.lang, region.ranges, depth+1)
+		if err != nil {
+			return nil, err
+		}
+		todos = append(todos, injected...)
+	}
+	return todos, nil
+}
+
+// clipRanges returns the parts of r which are inside the parent ranges.
+// If parents is nil, r is returned unchanged.
+func clipRanges(r treesitter.Range, parents []treesitter.Range) []treesitter.Range {
+	if r.StartByte >= r.EndByte {
+		return nil
+	}
+	if parents == nil {
+		return []treesitter.Range{r}
+	}
+	var clipped []treesitter.Range
+	for _, p := range parents {
+		if p.EndByte <= r.StartByte || p.StartByte >= r.EndByte {
+			continue
+		}
+		c := r
+		if p.StartByte > c.StartByte {
+			c.StartByte = p.StartByte
+			c.StartPoint = p.StartPoint
+		}
+		if p.EndByte < c.EndByte {
+			c.EndByte = p.EndByte
+			c.EndPoint = p.EndPoint
+		}
+		clipped = append(clipped, c)
+	}
+	return clipped
+}
@@ -9,6 +9,7 @@ import (
 	c "github.com/tree-sitter/tree-sitter-c/bindings/go"
 	cpp "github.com/tree-sitter/tree-sitter-cpp/bindings/go"
 	css "github.com/tree-sitter/tree-sitter-css/bindings/go"
+	template "github.com/tree-sitter/tree-sitter-embedded-template/bindings/go"
 	golang "github.com/tree-sitter/tree-sitter-go/bindings/go"
 	html "github.com/tree-sitter/tree-sitter-html/bindings/go"
 	java "github.com/tree-sitter/tree-sitter-java/bindings/go"
@@ -22,46 +23,110 @@ import (
 	typescript "github.com/tree-sitter/tree-sitter-typescript/bindings/go"
 )
 
+// componentInjections parses the script and style blocks of single-file components
+// using the language in their lang attribute. JavaScript and CSS are used when
+// there's no lang attribute, or when its language isn't registered.
+const componentInjections = `
+	((script_element (start_tag) @_tag (raw_text) @injection.content)
+	 (#not-match? @_tag "\\slang=")
+	 (#set! injection.language "javascript"))
+	((script_element
+	   (start_tag (attribute (attribute_name) @_attr (quoted_attribute_value (attribute_value) @injection.language)))
+	   (raw_text) @injection.content)
+	 (#eq? @_attr "lang")
+	 (#set! injection.language "javascript"))
+	((style_element (start_tag) @_tag (raw_text) @injection.content)
+	 (#not-match? @_tag "\\slang=")
+	 (#set! injection.language "css"))
+	((style_element
+	   (start_tag (attribute (attribute_name) @_attr (quoted_attribute_value (attribute_value) @injection.language)))
+	   (raw_text) @injection.content)
+	 (#eq? @_attr "lang")
+	 (#set! injection.language "css"))
+`
+
 func init() {
 	RegisterLanguage(LanguageOptions{
 		Name:       "Golang",
 		Language:   treesitter.NewLanguage(golang.Language()),
 		Extensions: []string{".go"},
+		Aliases:    []string{"go"},
+		ExtraQuerySources: []string{`
+			((comment)+ @comment.doc
+			 .
+			 [(function_declaration) (method_declaration) (type_declaration) (const_declaration) (var_declaration)])
+		`},
 	})
 	RegisterLanguage(LanguageOptions{
-		Name:       "TypeScript",
-		Language:   treesitter.NewLanguage(typescript.LanguageTypescript()),
-		Extensions: []string{".ts"},
+		Name:         "TypeScript",
+		Language:     treesitter.NewLanguage(typescript.LanguageTypescript()),
+		Extensions:   []string{".ts"},
+		Aliases:      []string{"ts"},
+		Interpreters: []string{"ts-node"},
 	})
 	RegisterLanguage(LanguageOptions{
 		Name:       "TypeScript TSX",
 		Language:   treesitter.NewLanguage(typescript.LanguageTSX()),
 		Extensions: []string{".tsx"},
+		Aliases:    []string{"tsx", "typescriptreact"},
 	})
 	RegisterLanguage(LanguageOptions{
-		Name:       "JavaScript",
-		Language:   treesitter.NewLanguage(javascript.Language()),
-		Extensions: []string{".js"},
+		Name:         "JavaScript",
+		Language:     treesitter.NewLanguage(javascript.Language()),
+		Extensions:   []string{".js", ".mjs", ".cjs", ".jsx"},
+		Aliases:      []string{"js"},
+		Interpreters: []string{"node", "nodejs"},
 	})
 	RegisterLanguage(LanguageOptions{
-		Name:       "Ruby",
-		Language:   treesitter.NewLanguage(ruby.Language()),
-		Extensions: []string{".rb"},
+		Name:         "Ruby",
+		Language:     treesitter.NewLanguage(ruby.Language()),
+		Extensions:   []string{".rb"},
+		Aliases:      []string{"rb"},
+		Filenames:    []string{"Rakefile", "Gemfile", "Vagrantfile", "Guardfile", "Podfile", "Brewfile", "config.ru"},
+		Patterns:     []string{"*.gemspec", "*.rake"},
+		Interpreters: []string{"ruby", "jruby", "rake"},
 	})
 	RegisterLanguage(LanguageOptions{
 		Name:       "Rust",
 		Language:   treesitter.NewLanguage(rust.Language()),
 		Extensions: []string{".rs"},
+		Aliases:    []string{"rs"},
 	})
 	RegisterLanguage(LanguageOptions{
-		Name:       "Python",
-		Language:   treesitter.NewLanguage(python.Language()),
-		Extensions: []string{".py"},
+		Name:         "Python",
+		Language:     treesitter.NewLanguage(python.Language()),
+		Extensions:   []string{".py", ".pyi"},
+		Aliases:      []string{"py"},
+		Filenames:    []string{"SConstruct", "SConscript"},
+		Interpreters: []string{"python", "pypy"},
+		ExtraQuerySources: []string{`
+			(module . (expression_statement (string) @comment.docstring))
+			(function_definition body: (block . (expression_statement (string) @comment.docstring)))
+			(class_definition body: (block . (expression_statement (string) @comment.docstring)))
+		`},
 	})
 	RegisterLanguage(LanguageOptions{
 		Name:       "HTML",
 		Language:   treesitter.NewLanguage(html.Language()),
-		Extensions: []string{".html"},
+		Extensions: []string{".html", ".htm"},
+		Injections: `
+			((script_element (raw_text) @injection.content)
+			 (#set! injection.language "javascript"))
+			((style_element (raw_text) @injection.content)
+			 (#set! injection.language "css"))
+		`,
+	})
+	RegisterLanguage(LanguageOptions{
+		Name:       "Vue",
+		Language:   treesitter.NewLanguage(html.Language()),
+		Extensions: []string{".vue"},
+		Injections: componentInjections,
+	})
+	RegisterLanguage(LanguageOptions{
+		Name:       "Svelte",
+		Language:   treesitter.NewLanguage(html.Language()),
+		Extensions: []string{".svelte"},
+		Injections: componentInjections,
 	})
 	RegisterLanguage(LanguageOptions{
 		Name:       "CSS",
@@ -69,9 +134,29 @@ func init() {
 		Extensions: []string{".css"},
 	})
 	RegisterLanguage(LanguageOptions{
-		Name:       "Bash",
+		Name:         "Bash",
+		Language:     treesitter.NewLanguage(bash.Language()),
+		Extensions:   []string{".sh", ".bash"},
+		Aliases:      []string{"sh", "shell"},
+		Filenames:    []string{".bashrc", ".profile", "PKGBUILD", "APKBUILD"},
+		Patterns:     []string{".bash_*"},
+		Interpreters: []string{"sh", "bash", "dash"},
+	})
+	// Makefiles and Dockerfiles use the same # comments as shell scripts,
+	// and their recipes and RUN instructions are mostly shell, so they're
+	// parsed with the Bash grammar.
+	RegisterLanguage(LanguageOptions{
+		Name:       "Makefile",
 		Language:   treesitter.NewLanguage(bash.Language()),
-		Extensions: []string{".sh", ".bash"},
+		Extensions: []string{".mk", ".mak"},
+		Aliases:    []string{"make"},
+		Filenames:  []string{"Makefile", "makefile", "GNUmakefile"},
+	})
+	RegisterLanguage(LanguageOptions{
+		Name:      "Dockerfile",
+		Language:  treesitter.NewLanguage(bash.Language()),
+		Filenames: []string{"Dockerfile", "Containerfile"},
+		Patterns:  []string{"*.dockerfile", "Dockerfile.*"},
 	})
 	RegisterLanguage(LanguageOptions{
 		Name:       "C",
@@ -81,12 +166,14 @@ func init() {
 	RegisterLanguage(LanguageOptions{
 		Name:       "C++",
 		Language:   treesitter.NewLanguage(cpp.Language()),
-		Extensions: []string{".cpp", ".cc", ".hpp"},
+		Extensions: []string{".cpp", ".cc", ".cxx", ".hpp", ".hh", ".hxx"},
+		Aliases:    []string{"cpp"},
 	})
 	RegisterLanguage(LanguageOptions{
 		Name:       "C#",
 		Language:   treesitter.NewLanguage(csharp.Language()),
 		Extensions: []string{".cs"},
+		Aliases:    []string{"cs", "csharp"},
 	})
 	RegisterLanguage(LanguageOptions{
 		Name:       "Java",
@@ -107,10 +194,44 @@ func init() {
 		Name:       "PHP",
 		Language:   treesitter.NewLanguage(php.LanguagePHP()),
 		Extensions: []string{".php"},
-	})
-	RegisterLanguage(LanguageOptions{
-		Name:       "Scala",
-		Language:   treesitter.NewLanguage(scala.Language()),
-		Extensions: []string{".scala", ".sc"},
+		Injections: `
+			((text) @injection.content
+			 (#set! injection.language "html")
+			 (#set! injection.combined))
+		`,
+		Interpreters: []string{"php"},
+	})
+	RegisterLanguage(LanguageOptions{
+		Name:         "Scala",
+		Language:     treesitter.NewLanguage(scala.Language()),
+		Extensions:   []string{".scala", ".sc"},
+		Interpreters: []string{"scala"},
+	})
+	RegisterLanguage(LanguageOptions{
+		Name:       "ERB",
+		Language:   treesitter.NewLanguage(template.Language()),
+		Extensions: []string{".erb"},
+		Aliases:    []string{"eruby"},
+		Injections: `
+			((content) @injection.content
+			 (#set! injection.language "html")
+			 (#set! injection.combined))
+			((code) @injection.content
+			 (#set! injection.language "ruby")
+			 (#set! injection.combined))
+		`,
+	})
+	RegisterLanguage(LanguageOptions{
+		Name:       "EJS",
+		Language:   treesitter.NewLanguage(template.Language()),
+		Extensions: []string{".ejs"},
+		Injections: `
+			((content) @injection.content
+			 (#set! injection.language "html")
+			 (#set! injection.combined))
+			((code) @injection.content
+			 (#set! injection.language "javascript")
+			 (#set! injection.combined))
+		`,
 	})
 }
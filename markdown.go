@@ -0,0 +1,122 @@
+package todo
+
+import (
+	"bytes"
+	"cmp"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	treesitter "github.com/tree-sitter/go-tree-sitter"
+)
+
+// markdownExtensions are the extensions of Markdown files.
+var markdownExtensions = []string{".md", ".markdown"}
+
+// isMarkdown reports whether the file is a Markdown file.
+func isMarkdown(file string) bool {
+	return slices.Contains(markdownExtensions, strings.ToLower(filepath.Ext(file)))
+}
+
+// fencedBlock is a fenced code block in a Markdown file.
+type fencedBlock struct {
+	// lang is the first word of the info string.
+	lang string
+	// content is the range of the lines between the fences.
+	content treesitter.Range
+}
+
+// parseMarkdown parses a Markdown file as text, except for the fenced code
+// blocks whose info string names a registered language. Only the comments
+// in those blocks are parsed, using the block's language.
+func (r *Registry) parseMarkdown(file string, source []byte) ([]Todo, error) {
+	todos := r.ParseText(file, source)
+	for _, b := range fencedBlocks(source) {
+		lang, ok := r.Lookup(b.lang)
+		if !ok {
+			continue
+		}
+		start, end := int(b.content.StartByte), int(b.content.EndByte)
+		todos = slices.DeleteFunc(todos, func(t Todo) bool {
+			return t.Span.Start >= start && t.Span.Start < end
+		})
+		if start == end {
+			continue
+		}
+		code, err := r.parseRanges(file, source, lang, []treesitter.Range{b.content}, 1)
+		if err != nil {
+			return nil, err
+		}
+		todos = append(todos, code...)
+	}
+	slices.SortStableFunc(todos, func(a, b Todo) int {
+		return cmp.Compare(a.Span.Start, b.Span.Start)
+	})
+	return todos, nil
+}
+
+// fencedBlocks returns the fenced code blocks in the Markdown source.
+// A block which isn't closed continues to the end of the source.
+func fencedBlocks(source []byte) []fencedBlock {
+	var blocks []fencedBlock
+	var open *fencedBlock
+	var fence []byte
+	row, offset := 0, 0
+	for line := range bytes.Lines(source) {
+		next := offset + len(line)
+		text := bytes.TrimRight(line, "\r\n")
+		trimmed := bytes.TrimLeft(text, " ")
+		indented := len(text)-len(trimmed) > 3
+		switch {
+		case open == nil && !indented:
+			f := fenceOf(trimmed)
+			info := trimmed[len(f):]
+			// backtick fences can't have backticks in the info string
+			if f == nil || (f[0] == '`' && bytes.ContainsRune(info, '`')) {
+				break
+			}
+			open = &fencedBlock{
+				content: treesitter.Range{
+					StartByte:  uint(next),
+					StartPoint: treesitter.Point{Row: uint(row + 1)},
+				},
+			}
+			if fields := strings.Fields(string(info)); len(fields) > 0 {
+				open.lang = strings.Trim(fields[0], "{.}")
+			}
+			fence = f
+		case open != nil && !indented:
+			f := fenceOf(trimmed)
+			if f == nil || f[0] != fence[0] || len(f) < len(fence) || len(bytes.TrimSpace(trimmed[len(f):])) > 0 {
+				break
+			}
+			open.content.EndByte = uint(offset)
+			open.content.EndPoint = treesitter.Point{Row: uint(row)}
+			blocks = append(blocks, *open)
+			open = nil
+		}
+		row, offset = row+1, next
+	}
+	if open != nil {
+		open.content.EndByte = uint(len(source))
+		open.content.EndPoint = treesitter.Point{
+			Row:    uint(bytes.Count(source, []byte("\n"))),
+			Column: uint(len(source) - bytes.LastIndexByte(source, '\n') - 1),
+		}
+		blocks = append(blocks, *open)
+	}
+	return blocks
+}
+
+// fenceOf returns the code fence at the start of the line,
+// which is at least three backticks or tildes.
+func fenceOf(line []byte) []byte {
+	if len(line) == 0 || (line[0] != '`' && line[0] != '~') {
+		return nil
+	}
+	n := len(line) - len(bytes.TrimLeft(line, string(line[:1])))
+	if n < 3 {
+		return nil
+	}
+	return line[:n]
+}
@@ -0,0 +1,46 @@
+package todo
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseMarkdown(t *testing.T) {
+	source := "# Notes\n" +
+		"TODO: prose\n" +
+		"```go\n" +
+		"// TODO: code\n" +
+		"x := \"TODO: string\"\n" +
+		"```\n" +
+		"~~~ unknown\n" +
+		"TODO: unknown\n" +
+		"~~~\n" +
+		"````python title=\"a.py\"\n" +
+		"```\n" +
+		"# TODO: unclosed\n"
+	todos, err := Parse("README.md", []byte(source))
+	if err != nil {
+		t.Fatal(err)
+	}
+	type result struct {
+		Line        int
+		Description string
+		Kind        CommentKind
+	}
+	var got []result
+	for _, todo := range todos {
+		got = append(got, result{todo.Location.Line, todo.Description, todo.Kind})
+		if raw := source[todo.Span.Start:todo.Span.End]; raw != todo.Raw {
+			t.Errorf("Span = %q, want %q", raw, todo.Raw)
+		}
+	}
+	want := []result{
+		{Line: 2, Description: "prose", Kind: PlainText},
+		{Line: 4, Description: "code", Kind: LineComment},
+		{Line: 8, Description: "unknown", Kind: PlainText},
+		{Line: 12, Description: "unclosed", Kind: LineComment},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Parse() = %+v, want %+v", got, want)
+	}
+}
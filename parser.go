@@ -9,23 +9,32 @@ import (
 	"unicode"
 )
 
-// parseLine parses a single TODO line.
+// defaultKeywords are the keywords used by new registries.
+var defaultKeywords = []string{"TODO"}
+
+// parseLine parses a single TODO line starting with one of the keywords.
 // Does not set the Location or Line fields.
-func parseLine(line []byte) (Todo, bool) {
+// The Span is relative to the start of the line.
+func parseLine(line []byte, keywords []string) (Todo, bool) {
 	var t Todo
-	// ignore everything up to the first TODO
-	_, line, ok := bytes.Cut(line, []byte("TODO"))
+	// ignore everything up to the first keyword
+	keyword, rest, ok := cutKeyword(line, keywords)
 	if !ok {
 		return t, false
 	}
-	br := bufio.NewReader(bytes.NewReader(line))
-	// After "TODO", optional attributes in parentheses
+	rd := bytes.NewReader(rest)
+	br := bufio.NewReader(rd)
+	// offset returns the position of the reader in the line
+	offset := func() int {
+		return len(line) - rd.Len() - br.Buffered()
+	}
+	// After the keyword, optional attributes in parentheses
 	if err := skipWhite(br); err != nil && !errors.Is(err, io.EOF) {
 		return t, false
 	}
 	if peekByte(br) == '(' {
-		if err := parseAttributes(br, &t); err != nil {
-			return t, false
+		if err := parseAttributes(br, &t, offset); err != nil {
+			return Todo{}, false
 		}
 	}
 	// Skip whitespace
@@ -44,12 +53,58 @@ func parseLine(line []byte) (Todo, bool) {
 	}
 	// Remainder is the description
 	description, _ := io.ReadAll(br)
+	t.Keyword = keyword
 	t.Description = string(bytes.TrimSpace(description))
+	// the description is the remainder of the line, so its offset is known from its length
+	start := len(line) - len(bytes.TrimLeftFunc(description, unicode.IsSpace))
+	t.Span = Span{
+		Start: len(line) - len(rest) - len(keyword),
+		End:   start + len(t.Description),
+	}
+	t.Span.End = trimSpanEnd(line, t.Span)
+	t.Raw = string(line[t.Span.Start:t.Span.End])
+	for i := range t.Attributes {
+		a := &t.Attributes[i]
+		a.Span.End = trimSpanEnd(line, a.Span)
+		a.Raw = string(line[a.Span.Start:a.Span.End])
+		// the key cannot contain '=', so the value follows the first one
+		if j := strings.IndexByte(a.Raw, '='); j >= 0 {
+			value := strings.TrimLeftFunc(a.Raw[j+1:], unicode.IsSpace)
+			a.ValueSpan = Span{Start: a.Span.End - len(value), End: a.Span.End}
+		}
+	}
 	return t, true
 }
 
+// trimSpanEnd returns the end of the span with trailing whitespace excluded.
+func trimSpanEnd(text []byte, span Span) int {
+	return span.Start + len(bytes.TrimRightFunc(text[span.Start:span.End], unicode.IsSpace))
+}
+
+// cutKeyword returns the first of the keywords in the line and the text after it.
+// Keywords are matched case insensitively, and the keyword is returned as it's written in the line.
+// The longest keyword is used if several start at the same position.
+func cutKeyword(line []byte, keywords []string) (string, []byte, bool) {
+	for i := range line {
+		var keyword []byte
+		for _, k := range keywords {
+			if k == "" || len(k) <= len(keyword) || i+len(k) > len(line) {
+				continue
+			}
+			if bytes.EqualFold(line[i:i+len(k)], []byte(k)) {
+				keyword = line[i : i+len(k)]
+			}
+		}
+		if keyword != nil {
+			return string(keyword), line[i+len(keyword):], true
+		}
+	}
+	return "", nil, false
+}
+
 // parseAttributes consumes '(' ... ')' which may contain comma-separated attributes.
-func parseAttributes(br *bufio.Reader, t *Todo) error {
+// The offset function returns the current position, and is used to set the attribute spans.
+func parseAttributes(br *bufio.Reader, t *Todo, offset func() int) error {
 	// consume '('
 	if b, err := br.ReadByte(); err != nil || b != '(' {
 		return errors.New("expected '('")
@@ -68,10 +123,12 @@ func parseAttributes(br *bufio.Reader, t *Todo) error {
 			return nil
 		}
 		// parse one attribute
+		start := offset()
 		attr, err := parseOneAttribute(br)
 		if err != nil {
 			return err
 		}
+		attr.Span = Span{Start: start, End: offset()}
 		t.Attributes = append(t.Attributes, attr)
 		// after attribute, maybe ',' or ')'
 		if err := skipWhite(br); err != nil && !errors.Is(err, io.EOF) {
@@ -81,6 +138,9 @@ func parseAttributes(br *bufio.Reader, t *Todo) error {
 		if err != nil && !errors.Is(err, io.EOF) {
 			return err
 		}
+		if len(p) == 0 {
+			return errors.New("unterminated attribute list")
+		}
 		if len(p) == 1 && p[0] == ',' {
 			br.ReadByte() // consume ','
 			continue
@@ -0,0 +1,538 @@
+package todo
+
+import (
+	"bytes"
+	"cmp"
+	"fmt"
+	"path"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	treesitter "github.com/tree-sitter/go-tree-sitter"
+)
+
+// DefaultRegistry contains the built-in languages.
+// It is used by the package level functions.
+var DefaultRegistry = NewRegistry()
+
+// Registry is a set of languages and the keywords used to parse source files.
+// It is safe for concurrent use.
+type Registry struct {
+	mu        sync.Mutex
+	langs     []*LanguageOptions
+	overrides []languagePattern
+	keywords  []string
+
+	// indexes derived from langs
+	names        map[string]*LanguageOptions
+	extensions   map[string]*LanguageOptions
+	filenames    map[string]*LanguageOptions
+	interpreters map[string]*LanguageOptions
+	patterns     []languagePattern
+}
+
+// languagePattern associates a glob pattern with a language.
+// Overrides refer to the language by name, since it may be registered later,
+// and the patterns derived from langs refer to the language directly.
+type languagePattern struct {
+	pattern string
+	name    string
+	lang    *LanguageOptions
+}
+
+// NewRegistry returns a registry without any languages.
+// The only keyword is TODO.
+func NewRegistry() *Registry {
+	r := &Registry{keywords: defaultKeywords}
+	r.reindex()
+	return r
+}
+
+// Clone returns a copy of the registry which can be modified
+// independently of the original.
+func (r *Registry) Clone() *Registry {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	c := &Registry{
+		langs:     slices.Clone(r.langs),
+		overrides: slices.Clone(r.overrides),
+		keywords:  r.keywords,
+	}
+	c.reindex()
+	return c
+}
+
+// Register adds a language to the registry.
+// A language with the same name is replaced.
+// If no queries are provided, the default queries will be used.
+// A *QueryError is returned if any of the queries are invalid.
+func (r *Registry) Register(opt LanguageOptions) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err := compileQueries(&opt, r.keywords); err != nil {
+		return err
+	}
+	r.langs = slices.DeleteFunc(r.langs, func(l *LanguageOptions) bool {
+		return strings.EqualFold(l.Name, opt.Name)
+	})
+	r.langs = append(r.langs, &opt)
+	r.reindex()
+	return nil
+}
+
+// MustRegister is like Register but panics if the language is invalid.
+func (r *Registry) MustRegister(opt LanguageOptions) {
+	if err := r.Register(opt); err != nil {
+		panic(err)
+	}
+}
+
+// Unregister removes the named language from the registry.
+// It reports whether the language was found.
+func (r *Registry) Unregister(name string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	l, ok := r.names[strings.ToLower(name)]
+	if !ok {
+		return false
+	}
+	r.langs = slices.DeleteFunc(r.langs, func(x *LanguageOptions) bool {
+		return x == l
+	})
+	r.reindex()
+	return true
+}
+
+// Lookup returns the language with the given name or alias.
+// Names are case insensitive.
+func (r *Registry) Lookup(name string) (*LanguageOptions, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	l, ok := r.names[strings.ToLower(name)]
+	return l, ok
+}
+
+// Languages returns the registered languages sorted by name.
+func (r *Registry) Languages() []*LanguageOptions {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	langs := slices.Clone(r.langs)
+	slices.SortFunc(langs, func(a, b *LanguageOptions) int {
+		return cmp.Compare(a.Name, b.Name)
+	})
+	return langs
+}
+
+// AddExtensions maps additional file extensions to the named language.
+func (r *Registry) AddExtensions(name string, exts ...string) error {
+	return r.update(name, func(l *LanguageOptions) {
+		l.Extensions = append(slices.Clip(l.Extensions), exts...)
+	})
+}
+
+// SetQueries replaces the comment queries of the named language.
+func (r *Registry) SetQueries(name string, queries ...*treesitter.Query) error {
+	if len(queries) == 0 {
+		return fmt.Errorf("no queries for language: %s", name)
+	}
+	for i, query := range queries {
+		if err := checkCaptures(name, i, query); err != nil {
+			return err
+		}
+	}
+	return r.update(name, func(l *LanguageOptions) {
+		l.Queries = queries
+		l.defaultQueries = false
+	})
+}
+
+// Keywords returns the words which start a TODO.
+func (r *Registry) Keywords() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return slices.Clone(r.keywords)
+}
+
+// SetKeywords replaces the words which start a TODO.
+// Matching is case sensitive. The default queries of the registered
+// languages are rebuilt to match the new keywords.
+func (r *Registry) SetKeywords(keywords ...string) error {
+	keywords = slices.DeleteFunc(slices.Clone(keywords), func(k string) bool {
+		return k == ""
+	})
+	if len(keywords) == 0 {
+		return fmt.Errorf("no keywords")
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	langs := slices.Clone(r.langs)
+	for i, l := range langs {
+		if !l.defaultQueries {
+			continue
+		}
+		c := *l
+		c.Queries = nil
+		if err := compileQueries(&c, keywords); err != nil {
+			return err
+		}
+		langs[i] = &c
+	}
+	r.langs = langs
+	r.keywords = keywords
+	r.reindex()
+	return nil
+}
+
+// QueryError is returned when a language's query is invalid.
+type QueryError struct {
+	// Language is the name of the language.
+	Language string
+	// Index is the index of the query in LanguageOptions.Queries
+	// or LanguageOptions.QuerySources. Indexes past the end of QuerySources
+	// refer to LanguageOptions.ExtraQuerySources. It is -1 for the injections query.
+	Index int
+	// Source is the query source if it was compiled during registration.
+	Source string
+	// Err is the tree-sitter error if the query failed to compile.
+	Err *treesitter.QueryError
+	// Message describes a query which compiled, but is not usable.
+	Message string
+}
+
+// Error implements the error interface.
+func (e *QueryError) Error() string {
+	name := fmt.Sprintf("query %d", e.Index)
+	if e.Index < 0 {
+		name = "injections query"
+	}
+	if e.Err != nil {
+		return fmt.Sprintf("language %s: %s: %s (offset %d)", e.Language, name, e.Err, e.Err.Offset)
+	}
+	return fmt.Sprintf("language %s: %s: %s", e.Language, name, e.Message)
+}
+
+// Unwrap returns the underlying tree-sitter error.
+func (e *QueryError) Unwrap() error {
+	if e.Err == nil {
+		return nil
+	}
+	return e.Err
+}
+
+// compileQueries compiles the query sources of opt and validates all of its queries.
+// The default queries are used if the language has no queries, and only match
+// the comments which contain one of the keywords.
+func compileQueries(opt *LanguageOptions, keywords []string) error {
+	if opt.Language == nil {
+		return fmt.Errorf("language %s: missing tree-sitter language", opt.Name)
+	}
+	for i, query := range opt.Queries {
+		if err := checkCaptures(opt.Name, i, query); err != nil {
+			return err
+		}
+	}
+	opt.Queries = slices.Clip(opt.Queries)
+	for i, source := range opt.QuerySources {
+		query, err := treesitter.NewQuery(opt.Language, source)
+		if err != nil {
+			return &QueryError{Language: opt.Name, Index: i, Source: source, Err: err}
+		}
+		if err := checkCaptures(opt.Name, i, query); err != nil {
+			err.Source = source
+			return err
+		}
+		opt.Queries = append(opt.Queries, query)
+	}
+	opt.defaultQueries = len(opt.Queries) == 0
+	if opt.defaultQueries {
+		names := []string{"comment", "line_comment", "block_comment"}
+		for _, name := range names {
+			query, err := treesitter.NewQuery(
+				opt.Language,
+				fmt.Sprintf(`((%s) @comment (#match? @comment %s))`, name, keywordPattern(keywords)),
+			)
+			// not every grammar has every comment node type
+			if err == nil {
+				opt.Queries = append(opt.Queries, query)
+			}
+		}
+	}
+	if len(opt.Queries) == 0 {
+		return fmt.Errorf("language %s: no queries", opt.Name)
+	}
+	for i, source := range opt.ExtraQuerySources {
+		index := len(opt.QuerySources) + i
+		query, err := treesitter.NewQuery(opt.Language, source)
+		if err != nil {
+			return &QueryError{Language: opt.Name, Index: index, Source: source, Err: err}
+		}
+		if err := checkCaptures(opt.Name, index, query); err != nil {
+			err.Source = source
+			return err
+		}
+		opt.Queries = append(opt.Queries, query)
+	}
+	if opt.Injections != "" {
+		query, err := treesitter.NewQuery(opt.Language, opt.Injections)
+		if err != nil {
+			return &QueryError{Language: opt.Name, Index: -1, Source: opt.Injections, Err: err}
+		}
+		if _, ok := query.CaptureIndexForName("injection.content"); !ok {
+			return &QueryError{Language: opt.Name, Index: -1, Source: opt.Injections, Message: "missing @injection.content capture"}
+		}
+		opt.injections = query
+	}
+	return nil
+}
+
+// keywordPattern returns a quoted regular expression which matches any of the keywords, ignoring case.
+func keywordPattern(keywords []string) string {
+	quoted := make([]string, len(keywords))
+	for i, k := range keywords {
+		quoted[i] = regexp.QuoteMeta(k)
+	}
+	return strconv.Quote("(?i)" + strings.Join(quoted, "|"))
+}
+
+// checkCaptures returns an error if the query does not have a @comment
+// or @comment.<kind> capture.
+func checkCaptures(lang string, index int, query *treesitter.Query) *QueryError {
+	if !slices.ContainsFunc(query.CaptureNames(), isCommentCapture) {
+		return &QueryError{Language: lang, Index: index, Message: "missing @comment capture"}
+	}
+	return nil
+}
+
+// update replaces the named language with a modified copy.
+func (r *Registry) update(name string, modify func(l *LanguageOptions)) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	l, ok := r.names[strings.ToLower(name)]
+	if !ok {
+		return fmt.Errorf("unknown language: %s", name)
+	}
+	c := *l
+	modify(&c)
+	i := slices.Index(r.langs, l)
+	r.langs[i] = &c
+	r.reindex()
+	return nil
+}
+
+// Override forces files matching the glob pattern to be parsed
+// with the named language. Overrides take precedence over all other
+// detection methods and later overrides win over earlier ones.
+func (r *Registry) Override(pattern, name string) error {
+	if _, err := path.Match(pattern, ""); err != nil {
+		return fmt.Errorf("invalid pattern %q: %w", pattern, err)
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.names[strings.ToLower(name)]; !ok {
+		return fmt.Errorf("unknown language: %s", name)
+	}
+	r.overrides = append(r.overrides, languagePattern{pattern: pattern, name: name})
+	return nil
+}
+
+// LanguageFor returns the language for the given file name.
+// See Registry.Detect for the precedence order.
+func (r *Registry) LanguageFor(file string) (*LanguageOptions, bool) {
+	return r.Detect(file, nil)
+}
+
+// reindex rebuilds the lookup tables from the registered languages.
+// Later registrations take precedence over earlier ones.
+// The caller must hold r.mu.
+func (r *Registry) reindex() {
+	r.names = map[string]*LanguageOptions{}
+	r.extensions = map[string]*LanguageOptions{}
+	r.filenames = map[string]*LanguageOptions{}
+	r.interpreters = map[string]*LanguageOptions{}
+	r.patterns = nil
+	for _, l := range r.langs {
+		for _, ext := range l.Extensions {
+			r.extensions[ext] = l
+		}
+		for _, name := range l.Filenames {
+			r.filenames[name] = l
+		}
+		for _, pattern := range l.Patterns {
+			r.patterns = append(r.patterns, languagePattern{pattern: pattern, lang: l})
+		}
+		for _, name := range l.Interpreters {
+			r.interpreters[name] = l
+		}
+		r.names[strings.ToLower(l.Name)] = l
+		for _, name := range l.Aliases {
+			r.names[strings.ToLower(name)] = l
+		}
+	}
+}
+
+// Parse parses the source and returns all TODO comments.
+// Files without a registered language are parsed as text, except for
+// the fenced code blocks in Markdown files.
+func (r *Registry) Parse(file string, source []byte) ([]Todo, error) {
+	if lang, ok := r.Detect(file, source); ok {
+		return r.ParseCode(file, source, lang)
+	}
+	if isMarkdown(file) {
+		return r.parseMarkdown(file, source)
+	}
+	return r.ParseText(file, source), nil
+}
+
+// ParseText parses a text string and returns all TODO comments.
+func (r *Registry) ParseText(file string, text []byte) []Todo {
+	return parseText(file, text, r.Keywords())
+}
+
+// ParseCode parses the source code and returns all TODO comments.
+// If lang is nil, the language is detected from the file name and source.
+func (r *Registry) ParseCode(file string, source []byte, opt *LanguageOptions) ([]Todo, error) {
+	if opt == nil {
+		var ok bool
+		opt, ok = r.Detect(file, source)
+		if !ok {
+			return nil, fmt.Errorf("no language for file: %s", file)
+		}
+	}
+	todos, err := r.parseRanges(file, source, opt, nil, 0)
+	if err != nil {
+		return nil, err
+	}
+	slices.SortStableFunc(todos, func(a, b Todo) int {
+		return cmp.Compare(a.Location.Line, b.Location.Line)
+	})
+	return todos, nil
+}
+
+// parseRanges parses the source and returns the TODO comments.
+// If ranges is not nil, only those parts of the source are parsed.
+func (r *Registry) parseRanges(file string, source []byte, opt *LanguageOptions, ranges []treesitter.Range, depth int) ([]Todo, error) {
+	parser := treesitter.NewParser()
+	defer parser.Close()
+	parser.SetLanguage(opt.Language)
+	if ranges != nil {
+		if err := parser.SetIncludedRanges(ranges); err != nil {
+			return nil, err
+		}
+	}
+	tree := parser.Parse(source, nil)
+	defer tree.Close()
+	return r.treeTodos(file, source, opt, tree, ranges, depth)
+}
+
+// treeTodos returns the TODO comments in a parsed tree, including the injected languages.
+func (r *Registry) treeTodos(file string, source []byte, opt *LanguageOptions, tree *treesitter.Tree, ranges []treesitter.Range, depth int) ([]Todo, error) {
+	var todos []Todo
+	keywords := r.Keywords()
+	for _, c := range r.captureComments(opt, tree, source, nil) {
+		todos = append(todos, c.todos(file, source, keywords)...)
+	}
+	if opt.injections != nil {
+		injected, err := r.parseInjections(file, source, opt, tree, ranges, depth)
+		if err != nil {
+			return nil, err
+		}
+		todos = append(todos, injected...)
+	}
+	return todos, nil
+}
+
+// capturedComment is a comment node found by one of the language's queries.
+type capturedComment struct {
+	start, end uint
+	row        uint
+	kind       CommentKind
+	explicit   bool
+}
+
+// todos parses the TODOs in the comment which start with one of the keywords.
+func (c capturedComment) todos(file string, source []byte, keywords []string) []Todo {
+	var todos []Todo
+	comment := source[c.start:c.end]
+	last := bytes.Count(comment, []byte("\n")) + 1
+	for _, todo := range parseText(file, comment, keywords) {
+		todo.Kind = c.kind
+		if todo.Kind != LineComment && todo.Location.Line == last {
+			description := trimCloser(todo.Description, todo.Kind)
+			todo.Span.End -= len(todo.Description) - len(description)
+			todo.Span.End = trimSpanEnd(comment, todo.Span)
+			todo.Raw = string(comment[todo.Span.Start:todo.Span.End])
+			todo.Description = description
+		}
+		todo.Location.Line += int(c.row)
+		todo.shift(int(c.start))
+		todo.Location.Column = columnAt(source, todo.Span.Start)
+		todos = append(todos, todo)
+	}
+	return todos
+}
+
+// captureComments runs the language's queries and returns the captured comments.
+// A node captured by multiple queries is only returned once, and an explicit
+// kind from a @comment.<kind> capture takes precedence over an inferred one.
+// If span is not nil, only the matches which intersect it are returned.
+func (r *Registry) captureComments(opt *LanguageOptions, tree *treesitter.Tree, source []byte, span *Span) []capturedComment {
+	var comments []capturedComment
+	seen := map[[2]uint]int{}
+	cursor := treesitter.NewQueryCursor()
+	defer cursor.Close()
+	if span != nil {
+		cursor.SetByteRange(uint(span.Start), uint(span.End))
+	}
+	for _, query := range opt.Queries {
+		names := query.CaptureNames()
+		captures := cursor.Captures(query, tree.RootNode(), source)
+		for {
+			m, index := captures.Next()
+			if m == nil {
+				break
+			}
+			capture := m.Captures[index]
+			name := names[capture.Index]
+			if !isCommentCapture(name) {
+				continue
+			}
+			node := capture.Node
+			kind, explicit := captureKind(name)
+			key := [2]uint{node.StartByte(), node.EndByte()}
+			if i, ok := seen[key]; ok {
+				if explicit && !comments[i].explicit {
+					comments[i].kind = kind
+					comments[i].explicit = true
+				}
+				continue
+			}
+			if !explicit {
+				kind = inferKind(source[key[0]:key[1]])
+			}
+			seen[key] = len(comments)
+			comments = append(comments, capturedComment{
+				start:    key[0],
+				end:      key[1],
+				row:      node.StartPosition().Row,
+				kind:     kind,
+				explicit: explicit,
+			})
+		}
+	}
+	return comments
+}
+
+// matchPattern reports whether the file matches the glob pattern.
+// Patterns without a '/' are matched against the base name only.
+func matchPattern(pattern, file string) bool {
+	file = filepath.ToSlash(file)
+	if !strings.Contains(pattern, "/") {
+		file = path.Base(file)
+	}
+	ok, _ := path.Match(pattern, file)
+	return ok
+}
@@ -0,0 +1,236 @@
+package todo
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	treesitter "github.com/tree-sitter/go-tree-sitter"
+)
+
+func TestRegistry(t *testing.T) {
+	golang, ok := DefaultRegistry.Lookup("go")
+	if !ok {
+		t.Fatal("missing Golang language")
+	}
+	reg := NewRegistry()
+	err := reg.Register(LanguageOptions{
+		Name:       golang.Name,
+		Language:   golang.Language,
+		Extensions: []string{".go"},
+	})
+	if err != nil {
+		t.Fatalf("Register() error = %v", err)
+	}
+	if _, ok := reg.LanguageFor("main.go"); !ok {
+		t.Fatal("LanguageFor(main.go) not found")
+	}
+	if _, ok := reg.LanguageFor("main.golang"); ok {
+		t.Fatal("LanguageFor(main.golang) found before AddExtensions")
+	}
+	if err := reg.AddExtensions("golang", ".golang"); err != nil {
+		t.Fatalf("AddExtensions() error = %v", err)
+	}
+	if l, ok := reg.LanguageFor("main.golang"); !ok || l.Name != "Golang" {
+		t.Fatalf("LanguageFor(main.golang) = %v, %v", l, ok)
+	}
+	if _, ok := DefaultRegistry.LanguageFor("main.golang"); ok {
+		t.Fatal("AddExtensions modified the default registry")
+	}
+	if err := reg.AddExtensions("Lua", ".lua"); err == nil {
+		t.Fatal("AddExtensions(Lua) expected error")
+	}
+	if !reg.Unregister("Golang") {
+		t.Fatal("Unregister(Golang) = false")
+	}
+	if reg.Unregister("Golang") {
+		t.Fatal("Unregister(Golang) = true after removal")
+	}
+	if _, ok := reg.LanguageFor("main.go"); ok {
+		t.Fatal("LanguageFor(main.go) found after Unregister")
+	}
+	if len(reg.Languages()) != 0 {
+		t.Fatalf("Languages() = %v, want empty", reg.Languages())
+	}
+}
+
+func TestRegistryReplace(t *testing.T) {
+	reg := DefaultRegistry.Clone()
+	golang, _ := reg.Lookup("Golang")
+	query, qerr := treesitter.NewQuery(golang.Language, `(interpreted_string_literal) @comment`)
+	if qerr != nil {
+		t.Fatalf("NewQuery() error = %v", qerr)
+	}
+	if err := reg.SetQueries("Golang", query); err != nil {
+		t.Fatalf("SetQueries() error = %v", err)
+	}
+	source := []byte("package main\n\n// TODO: ignored\nvar s = \"TODO: found\"\n")
+	got, err := reg.Parse("main.go", source)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if len(got) != 1 || got[0].Description != `found"` {
+		t.Fatalf("Parse() = %v, want single todo", got)
+	}
+	got, err = DefaultRegistry.Parse("main.go", source)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if len(got) != 1 || got[0].Description != "ignored" {
+		t.Fatalf("default Parse() = %v, want single todo", got)
+	}
+}
+
+func TestRegistryOverride(t *testing.T) {
+	reg := DefaultRegistry.Clone()
+	if err := reg.Override("*.tmpl", "unknown"); err == nil {
+		t.Fatal("Override(unknown) expected error")
+	}
+	if err := reg.Override("vendor/*.js", "Python"); err != nil {
+		t.Fatalf("Override() error = %v", err)
+	}
+	if l, _ := reg.LanguageFor("vendor/lib.js"); l.Name != "Python" {
+		t.Fatalf("LanguageFor(vendor/lib.js) = %s, want Python", l.Name)
+	}
+	if l, _ := reg.LanguageFor("lib.js"); l.Name != "JavaScript" {
+		t.Fatalf("LanguageFor(lib.js) = %s, want JavaScript", l.Name)
+	}
+	if l, _ := DefaultRegistry.LanguageFor("vendor/lib.js"); l.Name != "JavaScript" {
+		t.Fatalf("Override modified the default registry")
+	}
+	names := []string{}
+	for _, l := range reg.Languages() {
+		names = append(names, l.Name)
+	}
+	if !slices.IsSorted(names) || !slices.Contains(names, "Python") {
+		t.Fatalf("Languages() = %v", names)
+	}
+}
+
+func TestRegistryPatternAlias(t *testing.T) {
+	golang, _ := DefaultRegistry.Lookup("Golang")
+	reg := NewRegistry()
+	reg.MustRegister(LanguageOptions{
+		Name:     "Template",
+		Language: golang.Language,
+		Patterns: []string{"*.tmpl"},
+	})
+	// an alias which shadows the name of the language with the pattern
+	reg.MustRegister(LanguageOptions{
+		Name:     "Other",
+		Language: golang.Language,
+		Aliases:  []string{"template"},
+	})
+	if l, ok := reg.LanguageFor("page.tmpl"); !ok || l.Name != "Template" {
+		t.Fatalf("LanguageFor(page.tmpl) = %v, %v, want Template", l, ok)
+	}
+}
+
+func TestRegistryQueryErrors(t *testing.T) {
+	golang, _ := DefaultRegistry.Lookup("Golang")
+	tests := []struct {
+		name    string
+		opt     LanguageOptions
+		index   int
+		offset  uint
+		kind    treesitter.QueryErrorKind
+		message string
+	}{
+		{
+			name: "syntax error",
+			opt: LanguageOptions{
+				QuerySources: []string{`(comment) @comment`, `(comment @comment`},
+			},
+			index:  1,
+			offset: 9,
+			kind:   treesitter.QueryErrorSyntax,
+		},
+		{
+			name: "unknown node type",
+			opt: LanguageOptions{
+				QuerySources: []string{`(block_comment) @comment`},
+			},
+			index:  0,
+			offset: 1,
+			kind:   treesitter.QueryErrorNodeType,
+		},
+		{
+			name: "missing capture",
+			opt: LanguageOptions{
+				QuerySources: []string{`(comment) @todo`},
+			},
+			index:   0,
+			message: "missing @comment capture",
+		},
+		{
+			name: "invalid injections",
+			opt: LanguageOptions{
+				Injections: `(comment) @content`,
+			},
+			index:   -1,
+			message: "missing @injection.content capture",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.opt.Name = "Test"
+			tt.opt.Language = golang.Language
+			err := NewRegistry().Register(tt.opt)
+			var qerr *QueryError
+			if !errors.As(err, &qerr) {
+				t.Fatalf("Register() error = %v, want *QueryError", err)
+			}
+			if qerr.Index != tt.index {
+				t.Errorf("QueryError.Index = %d, want %d", qerr.Index, tt.index)
+			}
+			if tt.message != "" {
+				if qerr.Message != tt.message {
+					t.Errorf("QueryError.Message = %q, want %q", qerr.Message, tt.message)
+				}
+				return
+			}
+			if qerr.Err == nil {
+				t.Fatalf("QueryError.Err = nil")
+			}
+			if qerr.Err.Kind != tt.kind || qerr.Err.Offset != tt.offset {
+				t.Errorf("QueryError.Err = %v (kind=%d, offset=%d), want kind=%d, offset=%d",
+					qerr.Err, qerr.Err.Kind, qerr.Err.Offset, tt.kind, tt.offset)
+			}
+		})
+	}
+}
+
+func TestRegistryKeywords(t *testing.T) {
+	reg := DefaultRegistry.Clone()
+	if err := reg.SetKeywords("FIXME", "X.Y"); err != nil {
+		t.Fatalf("SetKeywords() error = %v", err)
+	}
+	if err := reg.SetKeywords(); err == nil {
+		t.Fatal("SetKeywords() with no keywords expected error")
+	}
+	source := []byte("// TODO: one\n// FIXME: two\n// XZY: three\n// X.Y: four\n")
+	todos, err := reg.Parse("main.go", source)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, t := range todos {
+		got = append(got, t.Keyword)
+	}
+	if want := []string{"FIXME", "X.Y"}; !slices.Equal(got, want) {
+		t.Fatalf("Parse() keywords = %q, want %q", got, want)
+	}
+	if todos, _ := Parse("main.go", source); len(todos) != 1 || todos[0].Keyword != "TODO" {
+		t.Fatalf("SetKeywords modified the default registry: %v", todos)
+	}
+	// only the comments containing a keyword are captured
+	golang, _ := reg.Lookup("go")
+	parser := treesitter.NewParser()
+	defer parser.Close()
+	parser.SetLanguage(golang.Language)
+	tree := parser.Parse(source, nil)
+	defer tree.Close()
+	if comments := reg.captureComments(golang, tree, source, nil); len(comments) != 2 {
+		t.Fatalf("captureComments() = %d comments, want 2", len(comments))
+	}
+}
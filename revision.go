@@ -0,0 +1,205 @@
+package todo
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// Repository reads files directly from a local git repository's
+// object store, so revisions can be scanned without checking them out.
+// Parsed blobs are cached, so scanning many similar revisions is cheap.
+// A Repository is not safe for concurrent use.
+type Repository struct {
+	// Registry is used to parse the files.
+	Registry *Registry
+
+	dir   string
+	cmd   *exec.Cmd
+	in    io.WriteCloser
+	out   *bufio.Reader
+	cache map[string][]Todo
+}
+
+// OpenRepository opens the git repository containing dir.
+// The repository must be closed after use.
+func OpenRepository(dir string) (*Repository, error) {
+	out, err := git(dir, "rev-parse", "--show-toplevel")
+	if err != nil {
+		return nil, err
+	}
+	r := &Repository{
+		Registry: DefaultRegistry,
+		dir:      strings.TrimSpace(string(out)),
+		cache:    map[string][]Todo{},
+	}
+	r.cmd = exec.Command("git", "cat-file", "--batch")
+	r.cmd.Dir = r.dir
+	if r.in, err = r.cmd.StdinPipe(); err != nil {
+		return nil, err
+	}
+	stdout, err := r.cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	r.out = bufio.NewReader(stdout)
+	if err := r.cmd.Start(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Dir returns the root directory of the repository's working tree.
+func (r *Repository) Dir() string {
+	return r.dir
+}
+
+// Close stops the git process used to read objects.
+func (r *Repository) Close() error {
+	r.in.Close()
+	return r.cmd.Wait()
+}
+
+// blob is a file in a git tree.
+type blob struct {
+	hash string
+	path string
+}
+
+// files lists the blobs in the revision, optionally limited by pathspecs
+// which are relative to dir. Without pathspecs, the whole tree is listed.
+// Submodules and symlinks are not included.
+func (r *Repository) files(dir, rev string, paths ...string) ([]blob, error) {
+	args := []string{"ls-tree", "-r", "-z", "--full-name"}
+	if len(paths) == 0 {
+		args = append(args, "--full-tree")
+	}
+	args = append(args, rev, "--")
+	out, err := git(dir, append(args, paths...)...)
+	if err != nil {
+		return nil, err
+	}
+	var blobs []blob
+	for entry := range strings.SplitSeq(string(out), "\x00") {
+		// <mode> SP <type> SP <hash> TAB <path>
+		info, path, ok := strings.Cut(entry, "\t")
+		if !ok {
+			continue
+		}
+		fields := strings.Fields(info)
+		if len(fields) != 3 || fields[1] != "blob" || fields[0] == "120000" {
+			continue
+		}
+		blobs = append(blobs, blob{hash: fields[2], path: path})
+	}
+	return blobs, nil
+}
+
+// ReadFile returns the contents of the file at the given revision.
+// The path is relative to the repository root.
+func (r *Repository) ReadFile(rev, path string) ([]byte, error) {
+	return r.object(rev + ":" + path)
+}
+
+// object reads an object from the git object store.
+func (r *Repository) object(name string) ([]byte, error) {
+	if strings.ContainsAny(name, "\n") {
+		return nil, fmt.Errorf("invalid object name: %q", name)
+	}
+	if _, err := fmt.Fprintln(r.in, name); err != nil {
+		return nil, err
+	}
+	// <hash> SP <type> SP <size> LF <contents> LF
+	header, err := r.out.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	fields := strings.Fields(header)
+	if len(fields) == 2 && fields[1] == "missing" {
+		return nil, fmt.Errorf("git object not found: %s", name)
+	}
+	if len(fields) != 3 {
+		return nil, fmt.Errorf("invalid git cat-file header: %q", header)
+	}
+	size, err := strconv.Atoi(fields[2])
+	if err != nil {
+		return nil, fmt.Errorf("invalid git cat-file header: %q", header)
+	}
+	data := make([]byte, size+1)
+	if _, err := io.ReadFull(r.out, data); err != nil {
+		return nil, err
+	}
+	return data[:size], nil
+}
+
+// Parse parses the files in the revision without checking it out.
+// If paths are provided, only files matching those git pathspecs are parsed.
+// The pathspecs are relative to the repository root.
+// Binary files are skipped. The Location.File of each TODO is the
+// slash separated path relative to the repository root.
+func (r *Repository) Parse(rev string, paths ...string) ([]Todo, error) {
+	return r.parse(r.dir, rev, paths...)
+}
+
+// parse parses the files in the revision matching pathspecs relative to dir.
+func (r *Repository) parse(dir, rev string, paths ...string) ([]Todo, error) {
+	blobs, err := r.files(dir, rev, paths...)
+	if err != nil {
+		return nil, err
+	}
+	var todos []Todo
+	for _, b := range blobs {
+		parsed, err := r.parseBlob(b)
+		if err != nil {
+			return nil, err
+		}
+		todos = append(todos, parsed...)
+	}
+	return todos, nil
+}
+
+// parseBlob parses a blob, using the cache if it has been parsed before.
+func (r *Repository) parseBlob(b blob) ([]Todo, error) {
+	key := b.hash + "\x00" + b.path
+	if todos, ok := r.cache[key]; ok {
+		return todos, nil
+	}
+	source, err := r.object(b.hash)
+	if err != nil {
+		return nil, err
+	}
+	var todos []Todo
+	if !isBinary(source) {
+		todos, err = r.Registry.Parse(b.path, source)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", b.path, err)
+		}
+	}
+	r.cache[key] = todos
+	return todos, nil
+}
+
+// ParseRevision parses the files in a git revision without checking it out.
+// The dir may be any directory in the repository, and the pathspecs are
+// relative to it, like they are for git commands run in that directory.
+// See Repository.Parse for details.
+func ParseRevision(dir, rev string, paths ...string) ([]Todo, error) {
+	repo, err := OpenRepository(dir)
+	if err != nil {
+		return nil, err
+	}
+	defer repo.Close()
+	return repo.parse(dir, rev, paths...)
+}
+
+// isBinary reports whether the data looks like a binary file.
+func isBinary(data []byte) bool {
+	if len(data) > 8000 {
+		data = data[:8000]
+	}
+	return bytes.IndexByte(data, 0) >= 0
+}
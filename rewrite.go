@@ -0,0 +1,70 @@
+package todo
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// SetAttribute sets the value of the attribute with the given key,
+// or appends a new attribute if the key doesn't exist.
+// The value is quoted if the existing attribute was quoted, or if it
+// can't be represented unquoted.
+func (t *Todo) SetAttribute(key, value string) {
+	for i, a := range t.Attributes {
+		if a.Key == key {
+			t.Attributes[i].Value = value
+			t.Attributes[i].Quote = a.Quote || needsQuote(value)
+			return
+		}
+	}
+	t.Attributes = append(t.Attributes, Attribute{
+		Key:   key,
+		Value: value,
+		Quote: needsQuote(value),
+	})
+}
+
+// RemoveAttribute removes all attributes with the given key.
+// It reports whether any attributes were removed.
+func (t *Todo) RemoveAttribute(key string) bool {
+	n := len(t.Attributes)
+	t.Attributes = slices.DeleteFunc(t.Attributes, func(a Attribute) bool {
+		return a.Key == key
+	})
+	return len(t.Attributes) != n
+}
+
+// needsQuote reports whether the value must be quoted to be parsed correctly.
+func needsQuote(value string) bool {
+	return strings.ContainsAny(value, ",()\"\\ \t") || strings.HasPrefix(value, "=")
+}
+
+// Rewrite replaces the text of each of the TODOs in the source with Todo.Text().
+// The TODOs must have been parsed from the same source and then modified.
+// Only the text covered by the Span is replaced, so the comment delimiters,
+// indentation and any text before the keyword are preserved, as is the
+// original syntax of the unmodified attributes.
+func Rewrite(source []byte, todos []Todo) ([]byte, error) {
+	todos = slices.Clone(todos)
+	slices.SortFunc(todos, func(a, b Todo) int {
+		return cmp.Compare(a.Span.Start, b.Span.Start)
+	})
+	var b []byte
+	offset := 0
+	for _, t := range todos {
+		if t.Span.Start < offset || t.Span.End < t.Span.Start || t.Span.End > len(source) {
+			return nil, fmt.Errorf("%s: invalid span %d-%d", t.Location, t.Span.Start, t.Span.End)
+		}
+		text := t.Text()
+		if strings.ContainsAny(text, "\r\n") {
+			return nil, fmt.Errorf("%s: TODO cannot contain a line break", t.Location)
+		}
+		b = append(b, source[offset:t.Span.Start]...)
+		b = append(b, text...)
+		offset = t.Span.End
+	}
+	b = append(b, source[offset:]...)
+	return b, nil
+}
@@ -0,0 +1,126 @@
+package todo
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRewrite(t *testing.T) {
+	tests := []struct {
+		name   string
+		file   string
+		source string
+		edit   func(t *Todo)
+		want   string
+	}{
+		{
+			name:   "set attribute",
+			file:   "main.go",
+			source: "package main\n\n\t// TODO(owner=alice): fix this\nfunc main() {}\n",
+			edit:   func(t *Todo) { t.SetAttribute("owner", "bob") },
+			want:   "package main\n\n\t// TODO(owner=bob): fix this\nfunc main() {}\n",
+		},
+		{
+			name:   "add quoted attribute",
+			file:   "main.go",
+			source: "// TODO: fix this\n",
+			edit:   func(t *Todo) { t.SetAttribute("deadline", "June 2025") },
+			want:   "// TODO(deadline=\"June 2025\"): fix this\n",
+		},
+		{
+			name:   "preserve quoting",
+			file:   "main.go",
+			source: "// TODO(owner=\"alice\"): fix this\n",
+			edit:   func(t *Todo) { t.SetAttribute("owner", "bob") },
+			want:   "// TODO(owner=\"bob\"): fix this\n",
+		},
+		{
+			name:   "non-ascii",
+			file:   "main.go",
+			source: "// TODO(owner=alice): café\n",
+			edit:   func(t *Todo) { t.SetAttribute("owner", "bob") },
+			want:   "// TODO(owner=bob): café\n",
+		},
+		{
+			name:   "preserve syntax",
+			file:   "main.go",
+			source: "// TODO (owner = alice,  note=\"a\\n\" ) : fix this\n// TODO (x) : unchanged\n",
+			edit: func(t *Todo) {
+				if _, ok := t.Attribute("owner"); ok {
+					t.SetAttribute("owner", "bob")
+				}
+			},
+			want: "// TODO (owner = bob,  note=\"a\\n\" ) : fix this\n// TODO (x) : unchanged\n",
+		},
+		{
+			name:   "remove attribute",
+			file:   "main.go",
+			source: "// TODO(owner=alice, issue=12): fix this\n",
+			edit:   func(t *Todo) { t.RemoveAttribute("owner") },
+			want:   "// TODO(issue=12): fix this\n",
+		},
+		{
+			name:   "block comment",
+			file:   "main.c",
+			source: "/* TODO: fix this */\nint x;\n",
+			edit:   func(t *Todo) { t.Description = "fix that" },
+			want:   "/* TODO: fix that */\nint x;\n",
+		},
+		{
+			name:   "multiple",
+			file:   "notes.txt",
+			source: "- TODO(owner=alice): one\n- TODO: two\n- TODO(owner=alice): three\n",
+			edit: func(t *Todo) {
+				if owner, _ := t.Attribute("owner"); owner == "alice" {
+					t.SetAttribute("owner", "bob")
+				}
+			},
+			want: "- TODO(owner=bob): one\n- TODO: two\n- TODO(owner=bob): three\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			todos, err := Parse(tt.file, []byte(tt.source))
+			if err != nil {
+				t.Fatal(err)
+			}
+			for i := range todos {
+				tt.edit(&todos[i])
+			}
+			got, err := Rewrite([]byte(tt.source), todos)
+			if err != nil {
+				t.Fatalf("Rewrite() error = %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("Rewrite() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRewriteErrors(t *testing.T) {
+	source := []byte("// TODO: one\n")
+	todos := ParseText("a.txt", source)
+	todos[0].Description = "two\nthree"
+	if _, err := Rewrite(source, todos); err == nil {
+		t.Errorf("Rewrite() with a line break: expected error")
+	}
+	todos = ParseText("a.txt", source)
+	todos = append(todos, todos[0])
+	if _, err := Rewrite(source, todos); err == nil {
+		t.Errorf("Rewrite() with overlapping spans: expected error")
+	}
+}
+
+func TestRemoveAttribute(t *testing.T) {
+	todo := Todo{Attributes: []Attribute{{Key: "a"}, {Key: "b"}, {Key: "a", Value: "1"}}}
+	if !todo.RemoveAttribute("a") {
+		t.Errorf("RemoveAttribute() = false, want true")
+	}
+	if want := []Attribute{{Key: "b"}}; !reflect.DeepEqual(todo.Attributes, want) {
+		t.Errorf("Attributes = %v, want %v", todo.Attributes, want)
+	}
+	if todo.RemoveAttribute("c") {
+		t.Errorf("RemoveAttribute() = true, want false")
+	}
+}
@@ -0,0 +1,81 @@
+package todo
+
+import (
+	"time"
+)
+
+// Severity is how urgent a TODO is.
+type Severity int
+
+const (
+	SeverityHint Severity = iota + 1
+	SeverityInfo
+	SeverityWarning
+	SeverityError
+)
+
+// String returns the name of the severity.
+func (s Severity) String() string {
+	switch s {
+	case SeverityHint:
+		return "hint"
+	case SeverityInfo:
+		return "info"
+	case SeverityWarning:
+		return "warning"
+	case SeverityError:
+		return "error"
+	default:
+		return "unknown"
+	}
+}
+
+// Deadline returns the value of the deadline or due attribute as a time.
+// The value may be a date (2006-01-02), or an RFC 3339 timestamp.
+// Dates are treated as the end of that day in UTC.
+func (t Todo) Deadline() (time.Time, bool) {
+	_, d, ok := t.deadline()
+	return d, ok
+}
+
+// deadline returns the value of the deadline or due attribute, and its time.
+// Attributes whose value isn't a date or timestamp are ignored.
+func (t Todo) deadline() (string, time.Time, bool) {
+	for _, key := range []string{"deadline", "due"} {
+		value, ok := t.Attribute(key)
+		if !ok {
+			continue
+		}
+		if d, err := time.Parse(time.DateOnly, value); err == nil {
+			return value, d.AddDate(0, 0, 1).Add(-time.Nanosecond), true
+		}
+		if d, err := time.Parse(time.RFC3339, value); err == nil {
+			return value, d, true
+		}
+	}
+	return "", time.Time{}, false
+}
+
+// Overdue reports whether the TODO has a deadline before now.
+func (t Todo) Overdue(now time.Time) bool {
+	d, ok := t.Deadline()
+	return ok && d.Before(now)
+}
+
+// Severity returns the severity of the TODO. Overdue TODOs are errors.
+// Otherwise, the severity is derived from the Priority: high priorities
+// (0 and 1) are warnings and low priorities (3 and 4) are hints.
+func (t Todo) Severity(now time.Time) Severity {
+	if t.Overdue(now) {
+		return SeverityError
+	}
+	priority, ok := t.Priority()
+	switch {
+	case ok && priority <= 1:
+		return SeverityWarning
+	case ok && priority >= 3:
+		return SeverityHint
+	default:
+		return SeverityInfo
+	}
+}
@@ -0,0 +1,35 @@
+package todo
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSeverity(t *testing.T) {
+	now := time.Date(2025, 3, 9, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		line string
+		want Severity
+	}{
+		{line: "TODO: fix", want: SeverityInfo},
+		{line: "TODO(priority=high): fix", want: SeverityWarning},
+		{line: "TODO(priority=P1): fix", want: SeverityWarning},
+		{line: "TODO(priority=low): fix", want: SeverityHint},
+		{line: "TODO(priority=medium): fix", want: SeverityInfo},
+		{line: "TODO(deadline=2025-03-09, priority=low): fix", want: SeverityHint},
+		{line: "TODO(deadline=2025-03-08, priority=low): fix", want: SeverityError},
+		{line: "TODO(due=2025-03-09T11:00:00Z): fix", want: SeverityError},
+		{line: "TODO(deadline=soon): fix", want: SeverityInfo},
+	}
+	for _, tt := range tests {
+		t.Run(tt.line, func(t *testing.T) {
+			todo, ok := parseLine([]byte(tt.line), defaultKeywords)
+			if !ok {
+				t.Fatalf("parseLine(%q) failed", tt.line)
+			}
+			if got := todo.Severity(now); got != tt.want {
+				t.Errorf("Severity() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,96 @@
+package todo
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ParseStaged parses the staged contents of the files changed in the index
+// and returns only the TODOs on added or modified lines.
+// Existing TODOs on unchanged lines are not included.
+// The Location.File of each TODO is the slash separated path relative to the repository root.
+func (r *Repository) ParseStaged() ([]Todo, error) {
+	out, err := git(r.dir, "diff", "--cached", "--name-only", "-z", "--no-renames", "--diff-filter=AM")
+	if err != nil {
+		return nil, err
+	}
+	var todos []Todo
+	for path := range strings.SplitSeq(string(out), "\x00") {
+		if path == "" {
+			continue
+		}
+		lines, err := r.stagedLines(path)
+		if err != nil {
+			return nil, err
+		}
+		if len(lines) == 0 {
+			continue
+		}
+		source, err := r.ReadFile("", path)
+		if err != nil {
+			return nil, err
+		}
+		if isBinary(source) {
+			continue
+		}
+		parsed, err := r.Registry.Parse(path, source)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		for _, t := range parsed {
+			if lines[t.Location.Line] {
+				todos = append(todos, t)
+			}
+		}
+	}
+	return todos, nil
+}
+
+// stagedLines returns the line numbers which were added or modified in the staged version of the file.
+func (r *Repository) stagedLines(path string) (map[int]bool, error) {
+	out, err := git(r.dir, "diff", "--cached", "--unified=0", "--no-color", "--no-ext-diff", "--", path)
+	if err != nil {
+		return nil, err
+	}
+	return parseHunks(string(out))
+}
+
+// parseHunks returns the new line numbers covered by the hunks in a unified diff.
+func parseHunks(diff string) (map[int]bool, error) {
+	lines := map[int]bool{}
+	for line := range strings.Lines(diff) {
+		// @@ -<start>[,<count>] +<start>[,<count>] @@
+		header, ok := strings.CutPrefix(line, "@@ ")
+		if !ok {
+			continue
+		}
+		fields := strings.Fields(header)
+		if len(fields) < 2 || !strings.HasPrefix(fields[1], "+") {
+			return nil, fmt.Errorf("invalid hunk header: %q", strings.TrimSpace(line))
+		}
+		start, count, err := parseRange(fields[1][1:])
+		if err != nil {
+			return nil, fmt.Errorf("invalid hunk header: %q", strings.TrimSpace(line))
+		}
+		for n := start; n < start+count; n++ {
+			lines[n] = true
+		}
+	}
+	return lines, nil
+}
+
+// parseRange parses a hunk range in the form <start>[,<count>].
+func parseRange(s string) (start, count int, err error) {
+	first, second, ok := strings.Cut(s, ",")
+	if start, err = strconv.Atoi(first); err != nil {
+		return 0, 0, err
+	}
+	if !ok {
+		return start, 1, nil
+	}
+	if count, err = strconv.Atoi(second); err != nil {
+		return 0, 0, err
+	}
+	return start, count, nil
+}
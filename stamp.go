@@ -0,0 +1,63 @@
+package todo
+
+import (
+	"path/filepath"
+	"time"
+)
+
+// Stamp adds the created attribute to the TODOs which don't have one, and
+// the author attribute if author is not empty. It returns the modified TODOs.
+//
+// The date and author of committed TODOs come from git blame. TODOs on
+// uncommitted lines, in untracked files, or outside of a git repository
+// are stamped with now and author. Dates are formatted in now's location,
+// so every TODO uses the same time zone.
+func Stamp(todos []Todo, now time.Time, author string) ([]Todo, error) {
+	var stamped []Todo
+	for _, t := range todos {
+		_, hasCreated := t.Attribute("created")
+		_, hasAuthor := t.Attribute("author")
+		if !hasCreated || (author != "" && !hasAuthor) {
+			stamped = append(stamped, t)
+		}
+	}
+	// only the TODOs in a repository can be blamed
+	repos := map[string]bool{}
+	var blamed []Todo
+	var indexes []int
+	for i, t := range stamped {
+		dir := filepath.Dir(t.Location.File)
+		ok, seen := repos[dir]
+		if !seen {
+			var err error
+			if ok, err = inRepository(dir); err != nil {
+				return nil, err
+			}
+			repos[dir] = ok
+		}
+		if ok {
+			blamed = append(blamed, t)
+			indexes = append(indexes, i)
+		}
+	}
+	if err := Blame(blamed); err != nil {
+		return nil, err
+	}
+	for j, i := range indexes {
+		stamped[i].Commit = blamed[j].Commit
+	}
+	for i := range stamped {
+		t := &stamped[i]
+		created, name := now, author
+		if t.Commit != nil {
+			created, name = t.Commit.AuthorTime.In(now.Location()), t.Commit.Author
+		}
+		if _, ok := t.Attribute("created"); !ok {
+			t.SetAttribute("created", created.Format(time.DateOnly))
+		}
+		if _, ok := t.Attribute("author"); author != "" && !ok {
+			t.SetAttribute("author", name)
+		}
+	}
+	return stamped, nil
+}
@@ -0,0 +1,72 @@
+package todo
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestStamp(t *testing.T) {
+	repo := newTestRepo(t)
+	repo.write("main.go", "package main\n\n// TODO: committed\n// TODO(created=2020-01-01): dated\n")
+	repo.commit("initial")
+	path := repo.write("main.go", "package main\n\n// TODO: committed\n// TODO(created=2020-01-01): dated\n// TODO: uncommitted\n")
+	outside := filepath.Join(t.TempDir(), "notes.txt")
+	if err := os.WriteFile(outside, []byte("TODO: outside\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	var todos []Todo
+	for _, file := range []string{path, outside} {
+		source, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		parsed, err := Parse(file, source)
+		if err != nil {
+			t.Fatal(err)
+		}
+		todos = append(todos, parsed...)
+	}
+	// the commit was at 2025-03-09T12:00:00Z, which is the next day in this zone
+	zone := time.FixedZone("UTC+13", 13*60*60)
+	now := time.Date(2025, 6, 1, 23, 30, 0, 0, zone)
+	t.Run("created", func(t *testing.T) {
+		stamped, err := Stamp(todos, now, "")
+		if err != nil {
+			t.Fatalf("Stamp() error = %v", err)
+		}
+		var got []string
+		for _, t := range stamped {
+			got = append(got, t.String())
+		}
+		want := []string{
+			"TODO(created=2025-03-10): committed",
+			"TODO(created=2025-06-01): uncommitted",
+			"TODO(created=2025-06-01): outside",
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("Stamp() = %q, want %q", got, want)
+		}
+	})
+	t.Run("author", func(t *testing.T) {
+		stamped, err := Stamp(todos, now, "Bob")
+		if err != nil {
+			t.Fatalf("Stamp() error = %v", err)
+		}
+		var got []string
+		for _, t := range stamped {
+			got = append(got, t.String())
+		}
+		want := []string{
+			"TODO(created=2025-03-10, author=Alice): committed",
+			"TODO(created=2020-01-01, author=Alice): dated",
+			"TODO(created=2025-06-01, author=Bob): uncommitted",
+			"TODO(created=2025-06-01, author=Bob): outside",
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("Stamp() = %q, want %q", got, want)
+		}
+	})
+}
@@ -1,59 +1,75 @@
 package todo
 
 import (
+	"bufio"
 	"bytes"
+	"cmp"
 	"fmt"
-	"path/filepath"
+	"slices"
 	"strings"
-	"sync"
 
 	treesitter "github.com/tree-sitter/go-tree-sitter"
 )
 
-var (
-	languagesMu sync.Mutex
-	languages   = map[string]*LanguageOptions{}
-)
-
 // LanguageOptions are treesitter options for a language.
 type LanguageOptions struct {
 	Name       string
 	Extensions []string
 	Language   *treesitter.Language
 	Queries    []*treesitter.Query
+
+	// QuerySources are compiled and added to Queries during registration.
+	// Each query must have a @comment capture, or a @comment.<kind> capture
+	// where kind is one of line, block, doc or docstring. The kind of
+	// @comment captures is inferred from the comment delimiters.
+	QuerySources []string
+	// ExtraQuerySources are like QuerySources, but are always used
+	// in addition to the default queries. They are used for nodes
+	// other than comments which may contain TODOs, such as docstrings.
+	ExtraQuerySources []string
+	// Filenames are exact base names such as "Rakefile".
+	Filenames []string
+	// Patterns are glob patterns matched against the base name,
+	// or against the whole slash separated path when they contain a '/'.
+	Patterns []string
+	// Interpreters are shebang interpreter names such as "python3".
+	Interpreters []string
+	// Aliases are the names used by vim and emacs modelines.
+	// The lower-cased Name is always accepted.
+	Aliases []string
+	// Injections is a query in the tree-sitter injections.scm format.
+	// See Registry.parseInjections for the supported captures and properties.
+	Injections string
+
+	injections *treesitter.Query
+	// defaultQueries is set when the queries were built from the
+	// registry's keywords, and must be rebuilt when they change.
+	defaultQueries bool
 }
 
-// RegisterLanguage registers a language with the given extensions.
+// RegisterLanguage registers a language with the default registry.
 // If no queries are provided, the default queries will be used.
+// It panics if any of the queries are invalid, use DefaultRegistry.Register
+// to handle the error instead.
 func RegisterLanguage(opt LanguageOptions) {
-	languagesMu.Lock()
-	defer languagesMu.Unlock()
-	if len(opt.Queries) == 0 {
-		names := []string{"comment", "line_comment", "block_comment"}
-		for _, name := range names {
-			query, err := treesitter.NewQuery(
-				opt.Language,
-				fmt.Sprintf(`(%s) @comment (#match? @comment "TODO")`, name),
-			)
-			if err == nil {
-				opt.Queries = append(opt.Queries, query)
-			}
-		}
-	}
-	if len(opt.Queries) == 0 {
-		panic(fmt.Sprintf("no queries for language: %s", opt.Name))
-	}
-	for _, ext := range opt.Extensions {
-		languages[ext] = &opt
-	}
+	DefaultRegistry.MustRegister(opt)
+}
+
+// OverrideLanguage forces files matching the glob pattern to be parsed
+// with the named language in the default registry.
+func OverrideLanguage(pattern, name string) error {
+	return DefaultRegistry.Override(pattern, name)
 }
 
-// LanguageFor returns the language for the given file name.
+// LanguageFor returns the language for the given file name from the default registry.
 func LanguageFor(file string) (*LanguageOptions, bool) {
-	languagesMu.Lock()
-	defer languagesMu.Unlock()
-	l, ok := languages[filepath.Ext(file)]
-	return l, ok
+	return DefaultRegistry.LanguageFor(file)
+}
+
+// DetectLanguage returns the language for the given file name and source
+// from the default registry.
+func DetectLanguage(file string, source []byte) (*LanguageOptions, bool) {
+	return DefaultRegistry.Detect(file, source)
 }
 
 // Attribute represents a key=value pair.
@@ -61,6 +77,13 @@ type Attribute struct {
 	Key   string
 	Value string
 	Quote bool
+	// Raw is the attribute exactly as it appeared in the source.
+	Raw string
+	// Span is the location of the Raw text in the source.
+	Span Span
+	// ValueSpan is the location of the value in the source, including quotes.
+	// It's empty if the attribute doesn't have a value.
+	ValueSpan Span
 }
 
 // String returns a string representation.
@@ -68,16 +91,41 @@ func (a Attribute) String() string {
 	if a.Value == "" {
 		return a.Key
 	}
+	return a.Key + "=" + a.valueString()
+}
+
+// valueString returns the value, quoted if necessary.
+func (a Attribute) valueString() string {
 	if a.Quote {
-		return fmt.Sprintf("%s=%q", a.Key, a.Value)
+		return quote(a.Value)
+	}
+	return a.Value
+}
+
+// quote returns the value as a quoted string which is parsed back to the same value.
+// Only backslashes and quotes are escaped.
+func quote(value string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(value) + `"`
+}
+
+// text returns the Raw text if the attribute hasn't been modified since it was parsed.
+// Otherwise, it returns the canonical string representation.
+func (a Attribute) text() string {
+	if a.Raw != "" {
+		if raw, err := parseOneAttribute(bufio.NewReader(strings.NewReader(a.Raw))); err == nil &&
+			raw.Key == a.Key && raw.Value == a.Value && raw.Quote == a.Quote {
+			return a.Raw
+		}
 	}
-	return fmt.Sprintf("%s=%s", a.Key, a.Value)
+	return a.String()
 }
 
 // Location represents a file location.
 type Location struct {
 	File string
 	Line int
+	// Column is the 1-based byte offset of the TODO keyword in the line.
+	Column int
 }
 
 // String returns a string representation of the location.
@@ -85,12 +133,29 @@ func (l Location) String() string {
 	return fmt.Sprintf("%s:%d", l.File, l.Line)
 }
 
+// Span is a range of byte offsets in a source file.
+type Span struct {
+	Start int
+	End   int
+}
+
 // Todo represents a TODO line.
 type Todo struct {
-	Line        string
-	Location    Location
+	Line     string
+	Location Location
+	// Span is the location of the TODO text in the source, from the
+	// start of the keyword to the end of the description.
+	// Comment delimiters are not included.
+	Span Span
+	// Raw is the text covered by the Span.
+	Raw         string
+	Keyword     string
 	Description string
 	Attributes  []Attribute
+	Kind        CommentKind
+	// Commit is the commit which introduced the TODO.
+	// It is only set by Blame.
+	Commit *Commit
 }
 
 // Attribute returns the value for the given key.
@@ -103,85 +168,126 @@ func (t Todo) Attribute(key string) (string, bool) {
 	return "", false
 }
 
+// shift moves the spans of the TODO and its attributes by n bytes.
+func (t *Todo) shift(n int) {
+	t.Span.Start += n
+	t.Span.End += n
+	for i := range t.Attributes {
+		a := &t.Attributes[i]
+		a.Span.Start += n
+		a.Span.End += n
+		if a.ValueSpan != (Span{}) {
+			a.ValueSpan.Start += n
+			a.ValueSpan.End += n
+		}
+	}
+}
+
 // String returns a string representation.
 func (t Todo) String() string {
+	return t.format(Attribute.String)
+}
+
+// Text returns the text of the TODO, preserving the original syntax of the
+// parts which haven't been modified since it was parsed. Unmodified TODOs
+// are returned byte-for-byte, and if only attribute values were modified,
+// just those values are replaced. Otherwise, the TODO is formatted with the
+// original syntax of the unmodified attributes. TODOs which weren't parsed
+// use the String representation.
+func (t Todo) Text() string {
+	if t.Raw == "" {
+		return t.String()
+	}
+	raw, ok := parseLine([]byte(t.Raw), []string{cmp.Or(t.Keyword, "TODO")})
+	if !ok || raw.Keyword != t.Keyword || raw.Description != t.Description ||
+		!slices.EqualFunc(raw.Attributes, t.Attributes, func(a, b Attribute) bool {
+			return a.Key == b.Key
+		}) {
+		return t.format(Attribute.text)
+	}
+	// the spans of the reparsed attributes are relative to the raw text,
+	// and they're replaced from last to first so they remain valid
+	text := t.Raw
+	for i := len(t.Attributes) - 1; i >= 0; i-- {
+		a, r := t.Attributes[i], raw.Attributes[i]
+		if a.Value == r.Value && a.Quote == r.Quote {
+			continue
+		}
+		if r.ValueSpan != (Span{}) && a.Value != "" {
+			text = text[:r.ValueSpan.Start] + a.valueString() + text[r.ValueSpan.End:]
+		} else {
+			text = text[:r.Span.Start] + a.String() + text[r.Span.End:]
+		}
+	}
+	return text
+}
+
+// format returns the TODO using the attr function to format the attributes.
+func (t Todo) format(attr func(Attribute) string) string {
 	var b strings.Builder
-	b.WriteString("TODO")
+	if t.Keyword == "" {
+		b.WriteString("TODO")
+	} else {
+		b.WriteString(t.Keyword)
+	}
 	if len(t.Attributes) > 0 {
 		b.WriteByte('(')
 		for i, a := range t.Attributes {
 			if i > 0 {
 				b.WriteString(", ")
 			}
-			b.WriteString(a.String())
+			b.WriteString(attr(a))
 		}
 		b.WriteByte(')')
 	}
-	b.WriteString(": ")
-	b.WriteString(t.Description)
+	b.WriteByte(':')
+	if t.Description != "" {
+		b.WriteByte(' ')
+		b.WriteString(t.Description)
+	}
 	return b.String()
 }
 
 // Parse parses the source and returns all TODO comments.
+// The language is detected using the default registry.
 func Parse(file string, source []byte) ([]Todo, error) {
-	if lang, ok := LanguageFor(file); ok {
-		return ParseCode(file, source, lang)
-	}
-	return ParseText(file, source), nil
+	return DefaultRegistry.Parse(file, source)
 }
 
 // ParseCode parses the source code and returns all TODO comments.
-// If lang is nil, the language is inferred from the file extension.
+// If lang is nil, the language is detected using the default registry.
 func ParseCode(file string, source []byte, opt *LanguageOptions) ([]Todo, error) {
-	if opt == nil {
-		var ok bool
-		opt, ok = LanguageFor(file)
-		if !ok {
-			return nil, fmt.Errorf("no language for file: %s", file)
-		}
-	}
-	var todos []Todo
-	parser := treesitter.NewParser()
-	defer parser.Close()
-	parser.SetLanguage(opt.Language)
-	tree := parser.Parse(source, nil)
-	defer tree.Close()
-	cursor := treesitter.NewQueryCursor()
-	defer cursor.Close()
-	for _, query := range opt.Queries {
-		captures := cursor.Captures(query, tree.RootNode(), source)
-		for {
-			m, index := captures.Next()
-			if m == nil {
-				break
-			}
-			node := m.Captures[index].Node
-			row := node.StartPosition().Row
-			comment := source[node.StartByte():node.EndByte()]
-			for _, todo := range ParseText(file, comment) {
-				todo.Location.Line += int(row)
-				todos = append(todos, todo)
-			}
-		}
-	}
-	return todos, nil
+	return DefaultRegistry.ParseCode(file, source, opt)
 }
 
-// ParseText parses a text string and returns all TODO comments.
+// ParseText parses a text string and returns all TODO comments using the default registry.
 func ParseText(file string, text []byte) []Todo {
+	return DefaultRegistry.ParseText(file, text)
+}
+
+// parseText parses each line of the text which starts with one of the keywords.
+func parseText(file string, text []byte, keywords []string) []Todo {
 	var todos []Todo
-	row := 0
+	row, offset := 0, 0
 	for line := range bytes.Lines(text) {
+		next := offset + len(line)
 		line = bytes.TrimRight(line, "\r\n")
-		if todo, ok := parseLine(line); ok {
+		if todo, ok := parseLine(line, keywords); ok {
 			todo.Line = string(line)
 			todo.Location = Location{
-				File: file,
-				Line: row + 1,
+				File:   file,
+				Line:   row + 1,
+				Column: todo.Span.Start + 1,
 			}
+			todo.shift(offset)
 			todos = append(todos, todo)
 		}
-		row++
+		row, offset = row+1, next
 	}
 	return todos
 }
+
+// columnAt returns the 1-based byte column of the offset in the source.
+func columnAt(source []byte, offset int) int {
+	return offset - bytes.LastIndexByte(source[:offset], '\n')
+}
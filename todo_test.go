@@ -3,6 +3,7 @@ package todo
 import (
 	"os"
 	"reflect"
+	"slices"
 	"testing"
 
 	sitter "github.com/tree-sitter/go-tree-sitter"
@@ -24,10 +25,15 @@ func TestParse(t *testing.T) {
 				{
 					Line: "// TODO: fix this",
 					Location: Location{
-						File: "test.go",
-						Line: 1,
+						File:   "test.go",
+						Line:   1,
+						Column: 4,
 					},
+					Span:        Span{Start: 3, End: 17},
+					Raw:         "TODO: fix this",
+					Keyword:     "TODO",
 					Description: "fix this",
+					Kind:        LineComment,
 				},
 			},
 		},
@@ -39,10 +45,15 @@ func TestParse(t *testing.T) {
 				{
 					Line: " TODO: does this work ?",
 					Location: Location{
-						File: "code.ts",
-						Line: 2,
+						File:   "code.ts",
+						Line:   2,
+						Column: 2,
 					},
+					Span:        Span{Start: 5, End: 27},
+					Raw:         "TODO: does this work ?",
+					Keyword:     "TODO",
 					Description: "does this work ?",
+					Kind:        BlockComment,
 				},
 			},
 		},
@@ -54,21 +65,187 @@ func TestParse(t *testing.T) {
 				{
 					Line: "// TODO(): fix this",
 					Location: Location{
-						File: "some.txt",
-						Line: 1,
+						File:   "some.txt",
+						Line:   1,
+						Column: 4,
 					},
+					Span:        Span{Start: 3, End: 19},
+					Raw:         "TODO(): fix this",
+					Keyword:     "TODO",
 					Description: "fix this",
 				},
 				{
 					Line: "TODO: fix this again",
 					Location: Location{
-						File: "some.txt",
-						Line: 2,
+						File:   "some.txt",
+						Line:   2,
+						Column: 1,
 					},
+					Span:        Span{Start: 20, End: 40},
+					Raw:         "TODO: fix this again",
+					Keyword:     "TODO",
 					Description: "fix this again",
 				},
 			},
 		},
+		{
+			name:   "html injections",
+			file:   "index.html",
+			source: []byte("<!-- TODO: html -->\n<script>\n  // TODO: javascript\n</script>\n<style>/* TODO: css */</style>\n"),
+			want: []Todo{
+				{
+					Line: "<!-- TODO: html -->",
+					Location: Location{
+						File:   "index.html",
+						Line:   1,
+						Column: 6,
+					},
+					Span:        Span{Start: 5, End: 15},
+					Raw:         "TODO: html",
+					Keyword:     "TODO",
+					Description: "html",
+					Kind:        BlockComment,
+				},
+				{
+					Line: "// TODO: javascript",
+					Location: Location{
+						File:   "index.html",
+						Line:   3,
+						Column: 6,
+					},
+					Span:        Span{Start: 34, End: 50},
+					Raw:         "TODO: javascript",
+					Keyword:     "TODO",
+					Description: "javascript",
+					Kind:        LineComment,
+				},
+				{
+					Line: "/* TODO: css */",
+					Location: Location{
+						File:   "index.html",
+						Line:   5,
+						Column: 11,
+					},
+					Span:        Span{Start: 71, End: 80},
+					Raw:         "TODO: css",
+					Keyword:     "TODO",
+					Description: "css",
+					Kind:        BlockComment,
+				},
+			},
+		},
+		{
+			name:   "nested injections",
+			file:   "index.php",
+			source: []byte("<div>\n<?php // TODO: php ?>\n<script>\n// TODO: javascript\n</script>\n</div>\n"),
+			want: []Todo{
+				{
+					Line: "// TODO: php ",
+					Location: Location{
+						File:   "index.php",
+						Line:   2,
+						Column: 10,
+					},
+					Span:        Span{Start: 15, End: 24},
+					Raw:         "TODO: php",
+					Keyword:     "TODO",
+					Description: "php",
+					Kind:        LineComment,
+				},
+				{
+					Line: "// TODO: javascript",
+					Location: Location{
+						File:   "index.php",
+						Line:   4,
+						Column: 4,
+					},
+					Span:        Span{Start: 40, End: 56},
+					Raw:         "TODO: javascript",
+					Keyword:     "TODO",
+					Description: "javascript",
+					Kind:        LineComment,
+				},
+			},
+		},
+		{
+			name:   "component injections",
+			file:   "App.vue",
+			source: []byte("<script lang=\"ts\">\n// TODO: typescript\nlet x: number = 1;\n</script>\n<style lang=\"scss\">/* TODO: scss */</style>\n<style>/* TODO: css */</style>\n"),
+			want: []Todo{
+				{
+					Line: "// TODO: typescript",
+					Location: Location{
+						File:   "App.vue",
+						Line:   2,
+						Column: 4,
+					},
+					Span:        Span{Start: 22, End: 38},
+					Raw:         "TODO: typescript",
+					Keyword:     "TODO",
+					Description: "typescript",
+					Kind:        LineComment,
+				},
+				{
+					Line: "/* TODO: scss */",
+					Location: Location{
+						File:   "App.vue",
+						Line:   5,
+						Column: 23,
+					},
+					Span:        Span{Start: 90, End: 100},
+					Raw:         "TODO: scss",
+					Keyword:     "TODO",
+					Description: "scss",
+					Kind:        BlockComment,
+				},
+				{
+					Line: "/* TODO: css */",
+					Location: Location{
+						File:   "App.vue",
+						Line:   6,
+						Column: 11,
+					},
+					Span:        Span{Start: 122, End: 131},
+					Raw:         "TODO: css",
+					Keyword:     "TODO",
+					Description: "css",
+					Kind:        BlockComment,
+				},
+			},
+		},
+		{
+			name:   "template injections",
+			file:   "show.html.erb",
+			source: []byte("<p><%= title # TODO: ruby %></p>\n<%# TODO: template %>\n"),
+			want: []Todo{
+				{
+					Line: "# TODO: ruby ",
+					Location: Location{
+						File:   "show.html.erb",
+						Line:   1,
+						Column: 16,
+					},
+					Span:        Span{Start: 15, End: 25},
+					Raw:         "TODO: ruby",
+					Keyword:     "TODO",
+					Description: "ruby",
+					Kind:        LineComment,
+				},
+				{
+					Line: " TODO: template ",
+					Location: Location{
+						File:   "show.html.erb",
+						Line:   2,
+						Column: 5,
+					},
+					Span:        Span{Start: 37, End: 51},
+					Raw:         "TODO: template",
+					Keyword:     "TODO",
+					Description: "template",
+					Kind:        LineComment,
+				},
+			},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -83,6 +260,80 @@ func TestParse(t *testing.T) {
 	}
 }
 
+func TestParseKind(t *testing.T) {
+	tests := []struct {
+		name        string
+		file        string
+		source      string
+		description string
+		kind        CommentKind
+	}{
+		{
+			name:        "go doc comment",
+			file:        "main.go",
+			source:      "package main\n\n// Foo does things.\n// TODO: document\nfunc Foo() {}\n",
+			description: "document",
+			kind:        DocComment,
+		},
+		{
+			name:        "rust doc comment",
+			file:        "main.rs",
+			source:      "/// TODO: document\nfn main() {}\n",
+			description: "document",
+			kind:        DocComment,
+		},
+		{
+			name:        "java doc comment",
+			file:        "Main.java",
+			source:      "/** TODO: document */\nclass Main {}\n",
+			description: "document",
+			kind:        DocComment,
+		},
+		{
+			name:        "ruby block comment",
+			file:        "main.rb",
+			source:      "=begin\nTODO: document\n=end\n",
+			description: "document",
+			kind:        BlockComment,
+		},
+		{
+			name:        "python docstring",
+			file:        "main.py",
+			source:      "def main():\n    \"\"\"TODO: document\"\"\"\n    pass\n",
+			description: "document",
+			kind:        Docstring,
+		},
+		{
+			name:        "python string",
+			file:        "main.py",
+			source:      "x = 1\ny = \"TODO: ignored\"\n# TODO: comment\n",
+			description: "comment",
+			kind:        LineComment,
+		},
+		{
+			name:        "text",
+			file:        "notes.txt",
+			source:      "TODO: write notes\n",
+			description: "write notes",
+			kind:        PlainText,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse(tt.file, []byte(tt.source))
+			if err != nil {
+				t.Fatalf("Parse(%q) error = %v", tt.file, err)
+			}
+			if len(got) != 1 {
+				t.Fatalf("Parse(%q) = %v, want 1 todo", tt.file, got)
+			}
+			if got[0].Description != tt.description || got[0].Kind != tt.kind {
+				t.Errorf("Parse(%q) = %q (%s), want %q (%s)", tt.file, got[0].Description, got[0].Kind, tt.description, tt.kind)
+			}
+		})
+	}
+}
+
 func TestParseLine(t *testing.T) {
 	tests := []struct {
 		name string
@@ -95,6 +346,9 @@ func TestParseLine(t *testing.T) {
 			line: "TODO: fix this",
 			ok:   true,
 			want: Todo{
+				Span:        Span{Start: 0, End: 14},
+				Raw:         "TODO: fix this",
+				Keyword:     "TODO",
 				Description: "fix this",
 				Attributes:  nil,
 			},
@@ -104,6 +358,11 @@ func TestParseLine(t *testing.T) {
 			line: "This is just a comment",
 			ok:   false,
 		},
+		{
+			name: "unterminated attribute list",
+			line: "TODO(owner",
+			ok:   false,
+		},
 		{
 			name: "do not match TODO without colon",
 			line: "TODO fix this",
@@ -114,6 +373,9 @@ func TestParseLine(t *testing.T) {
 			line: "TODO(): fix this",
 			ok:   true,
 			want: Todo{
+				Span:        Span{Start: 0, End: 16},
+				Raw:         "TODO(): fix this",
+				Keyword:     "TODO",
 				Description: "fix this",
 			},
 		},
@@ -122,10 +384,13 @@ func TestParseLine(t *testing.T) {
 			line: "TODO(created=2025-03-09,assigned=john): fix this",
 			ok:   true,
 			want: Todo{
+				Span:        Span{Start: 0, End: 48},
+				Raw:         "TODO(created=2025-03-09,assigned=john): fix this",
+				Keyword:     "TODO",
 				Description: "fix this",
 				Attributes: []Attribute{
-					{Key: "created", Value: "2025-03-09"},
-					{Key: "assigned", Value: "john"},
+					{Key: "created", Value: "2025-03-09", Raw: "created=2025-03-09", Span: Span{Start: 5, End: 23}, ValueSpan: Span{Start: 13, End: 23}},
+					{Key: "assigned", Value: "john", Raw: "assigned=john", Span: Span{Start: 24, End: 37}, ValueSpan: Span{Start: 33, End: 37}},
 				},
 			},
 		},
@@ -134,9 +399,12 @@ func TestParseLine(t *testing.T) {
 			line: `TODO(message="fix this, that, and the other"): implement feature`,
 			ok:   true,
 			want: Todo{
+				Span:        Span{Start: 0, End: 64},
+				Raw:         `TODO(message="fix this, that, and the other"): implement feature`,
+				Keyword:     "TODO",
 				Description: "implement feature",
 				Attributes: []Attribute{
-					{Key: "message", Value: "fix this, that, and the other", Quote: true},
+					{Key: "message", Value: "fix this, that, and the other", Quote: true, Raw: `message="fix this, that, and the other"`, Span: Span{Start: 5, End: 44}, ValueSpan: Span{Start: 13, End: 44}},
 				},
 			},
 		},
@@ -145,10 +413,13 @@ func TestParseLine(t *testing.T) {
 			line: `TODO(created=2023-01-01,message="complex, value)"): do something`,
 			ok:   true,
 			want: Todo{
+				Span:        Span{Start: 0, End: 64},
+				Raw:         `TODO(created=2023-01-01,message="complex, value)"): do something`,
+				Keyword:     "TODO",
 				Description: "do something",
 				Attributes: []Attribute{
-					{Key: "created", Value: "2023-01-01"},
-					{Key: "message", Value: "complex, value)", Quote: true},
+					{Key: "created", Value: "2023-01-01", Raw: "created=2023-01-01", Span: Span{Start: 5, End: 23}, ValueSpan: Span{Start: 13, End: 23}},
+					{Key: "message", Value: "complex, value)", Quote: true, Raw: `message="complex, value)"`, Span: Span{Start: 24, End: 49}, ValueSpan: Span{Start: 32, End: 49}},
 				},
 			},
 		},
@@ -157,9 +428,12 @@ func TestParseLine(t *testing.T) {
 			line: `TODO(message="value with \"escaped\" quotes"): task`,
 			ok:   true,
 			want: Todo{
+				Span:        Span{Start: 0, End: 51},
+				Raw:         `TODO(message="value with \"escaped\" quotes"): task`,
+				Keyword:     "TODO",
 				Description: "task",
 				Attributes: []Attribute{
-					{Key: "message", Value: `value with "escaped" quotes`, Quote: true},
+					{Key: "message", Value: `value with "escaped" quotes`, Quote: true, Raw: `message="value with \"escaped\" quotes"`, Span: Span{Start: 5, End: 44}, ValueSpan: Span{Start: 13, End: 44}},
 				},
 			},
 		},
@@ -168,9 +442,12 @@ func TestParseLine(t *testing.T) {
 			line: `TODO(path="C:\\Program Files\\App"): update path`,
 			ok:   true,
 			want: Todo{
+				Span:        Span{Start: 0, End: 48},
+				Raw:         `TODO(path="C:\\Program Files\\App"): update path`,
+				Keyword:     "TODO",
 				Description: "update path",
 				Attributes: []Attribute{
-					{Key: "path", Value: `C:\Program Files\App`, Quote: true},
+					{Key: "path", Value: `C:\Program Files\App`, Quote: true, Raw: `path="C:\\Program Files\\App"`, Span: Span{Start: 5, End: 34}, ValueSpan: Span{Start: 10, End: 34}},
 				},
 			},
 		},
@@ -179,11 +456,14 @@ func TestParseLine(t *testing.T) {
 			line: `TODO(key, 2025-03-06, author=icholy): description`,
 			ok:   true,
 			want: Todo{
+				Span:        Span{Start: 0, End: 49},
+				Raw:         "TODO(key, 2025-03-06, author=icholy): description",
+				Keyword:     "TODO",
 				Description: "description",
 				Attributes: []Attribute{
-					{Key: "key"},
-					{Key: "2025-03-06"},
-					{Key: "author", Value: "icholy"},
+					{Key: "key", Raw: "key", Span: Span{Start: 5, End: 8}},
+					{Key: "2025-03-06", Raw: "2025-03-06", Span: Span{Start: 10, End: 20}},
+					{Key: "author", Value: "icholy", Raw: "author=icholy", Span: Span{Start: 22, End: 35}, ValueSpan: Span{Start: 29, End: 35}},
 				},
 			},
 		},
@@ -192,10 +472,13 @@ func TestParseLine(t *testing.T) {
 			line: `   TODO (key = value, key2 =  "value" ) : description`,
 			ok:   true,
 			want: Todo{
+				Span:        Span{Start: 3, End: 53},
+				Raw:         `TODO (key = value, key2 =  "value" ) : description`,
+				Keyword:     "TODO",
 				Description: "description",
 				Attributes: []Attribute{
-					{Key: "key", Value: "value"},
-					{Key: "key2", Value: "value", Quote: true},
+					{Key: "key", Value: "value", Raw: "key = value", Span: Span{Start: 9, End: 20}, ValueSpan: Span{Start: 15, End: 20}},
+					{Key: "key2", Value: "value", Quote: true, Raw: `key2 =  "value"`, Span: Span{Start: 22, End: 37}, ValueSpan: Span{Start: 30, End: 37}},
 				},
 			},
 		},
@@ -204,6 +487,9 @@ func TestParseLine(t *testing.T) {
 			line: "# // * --- TODO: fix this",
 			ok:   true,
 			want: Todo{
+				Span:        Span{Start: 11, End: 25},
+				Raw:         "TODO: fix this",
+				Keyword:     "TODO",
 				Description: "fix this",
 				Attributes:  nil,
 			},
@@ -212,7 +498,7 @@ func TestParseLine(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got, ok := parseLine([]byte(tt.line))
+			got, ok := parseLine([]byte(tt.line), defaultKeywords)
 			if ok != tt.ok {
 				t.Fatalf("ParseLine(%q) = got ok=%v, want ok=%v", tt.line, ok, tt.ok)
 			}
@@ -334,3 +620,68 @@ func BenchmarkParseCode(b *testing.B) {
 		}
 	}
 }
+
+func TestKeywords(t *testing.T) {
+	keywords := []string{"TODO", "FIXME", "FIX"}
+	tests := []struct {
+		line    string
+		ok      bool
+		keyword string
+	}{
+		{line: "// TODO: one", ok: true, keyword: "TODO"},
+		{line: "// FIXME: two", ok: true, keyword: "FIXME"},
+		{line: "// FIX: three", ok: true, keyword: "FIX"},
+		{line: "// fixme: lowercase", ok: true, keyword: "fixme"},
+		{line: "// Fix: mixed case", ok: true, keyword: "Fix"},
+		{line: "// HACK: unknown", ok: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.line, func(t *testing.T) {
+			got, ok := parseLine([]byte(tt.line), keywords)
+			if ok != tt.ok {
+				t.Fatalf("parseLine() ok = %v, want %v", ok, tt.ok)
+			}
+			if got.Keyword != tt.keyword {
+				t.Errorf("parseLine() Keyword = %q, want %q", got.Keyword, tt.keyword)
+			}
+		})
+	}
+}
+
+func TestTodoText(t *testing.T) {
+	line := `TODO (owner = bob,note="a\n\"b\"" ) :  fix this`
+	parsed, ok := parseLine([]byte(line), defaultKeywords)
+	if !ok {
+		t.Fatalf("parseLine(%q) failed", line)
+	}
+	if got := parsed.Text(); got != line {
+		t.Errorf("Text() = %q, want %q", got, line)
+	}
+	edited := parsed
+	edited.Attributes = slices.Clone(parsed.Attributes)
+	edited.SetAttribute("owner", "alice")
+	if got, want := edited.Text(), `TODO (owner = alice,note="a\n\"b\"" ) :  fix this`; got != want {
+		t.Errorf("Text() = %q, want %q", got, want)
+	}
+	edited.SetAttribute("owner", "alice smith")
+	if got, want := edited.Text(), `TODO (owner = "alice smith",note="a\n\"b\"" ) :  fix this`; got != want {
+		t.Errorf("Text() = %q, want %q", got, want)
+	}
+	edited.SetAttribute("owner", "")
+	if got, want := edited.Text(), `TODO (owner,note="a\n\"b\"" ) :  fix this`; got != want {
+		t.Errorf("Text() = %q, want %q", got, want)
+	}
+	// other changes are formatted, keeping the syntax of the attributes
+	edited.Description = "fix that"
+	if got, want := edited.Text(), `TODO(owner, note="a\n\"b\""): fix that`; got != want {
+		t.Errorf("Text() = %q, want %q", got, want)
+	}
+	// the canonical quoting must parse back to the same value
+	reparsed, ok := parseLine([]byte(edited.String()), defaultKeywords)
+	if !ok {
+		t.Fatalf("parseLine(%q) failed", edited.String())
+	}
+	if got, want := reparsed.Attributes[1].Value, `a\n"b"`; got != want {
+		t.Errorf("Value = %q, want %q", got, want)
+	}
+}